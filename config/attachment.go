@@ -0,0 +1,114 @@
+// Copyright 2015 Prometheus Team
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/url"
+
+	"github.com/pkg/errors"
+)
+
+// Renderer produces a PNG image (typically a rendered chart or dashboard
+// screenshot) for an alert, to be uploaded and linked from the outgoing
+// notification. Implementations live outside this package (e.g. a Grafana
+// renderer) and are passed in by the caller that builds a notifier.
+// -------------------------------------------------------------------------
+// Renderer 负责为告警生成一张PNG图片（通常是图表或大盘截图），生成结果会
+// 被上传并在通知内容中以链接形式引用。具体实现（如调用Grafana渲染服务）
+// 不属于本包，由调用方在构造notifier时注入。
+type Renderer interface {
+	Render(ctx context.Context) (png []byte, err error)
+}
+
+// AttachmentUploader uploads a rendered image and returns a URL that can be
+// embedded in a notification (e.g. Slack's image_url, a DingTalk actionCard,
+// or a WeChat news article). Receivers that support inline images render via
+// a Renderer, then call Upload on the result.
+// -------------------------------------------------------------------------
+// AttachmentUploader 负责把渲染出的图片上传并返回可在通知内容中引用的URL，
+// 供Slack的image_url、钉钉actionCard、企业微信图文消息等渠道使用。
+type AttachmentUploader interface {
+	Upload(ctx context.Context, key string, png []byte) (url string, err error)
+}
+
+// Uploader returns the AttachmentUploader for this Config: an S3-backed one
+// when S3 is configured, or a no-op uploader otherwise so receivers can call
+// Upload unconditionally.
+func (c *Config) Uploader() AttachmentUploader {
+	if c.S3 == nil {
+		return noopUploader{}
+	}
+	return NewS3Uploader(c.S3)
+}
+
+type noopUploader struct{}
+
+// Upload implements AttachmentUploader by doing nothing; it is used when no
+// S3 block is configured so attachment-capable receivers degrade gracefully
+// to text-only notifications instead of failing.
+func (noopUploader) Upload(_ context.Context, _ string, _ []byte) (string, error) {
+	return "", nil
+}
+
+// S3Uploader is an AttachmentUploader backed by an S3 (or S3-compatible)
+// bucket, reusing the same S3Config used for remote template fetching.
+type S3Uploader struct {
+	conf   *S3Config
+	client *http.Client
+}
+
+// NewS3Uploader returns an AttachmentUploader for the given S3Config.
+func NewS3Uploader(c *S3Config) *S3Uploader {
+	return &S3Uploader{conf: c, client: &http.Client{}}
+}
+
+// Upload PUTs png to "<prefix/>key" in the configured bucket and returns the
+// URL it can be fetched back from.
+func (u *S3Uploader) Upload(ctx context.Context, key string, png []byte) (string, error) {
+	if u.conf.Prefix != "" {
+		key = u.conf.Prefix + "/" + key
+	}
+
+	scheme := "https"
+	if u.conf.Insecure {
+		scheme = "http"
+	}
+	// Built through url.URL rather than string concatenation so that a key
+	// containing "#", "?" or "%" is percent-encoded into the path instead
+	// of being parsed as a URL fragment/query (which http.NewRequest would
+	// otherwise silently truncate the request at).
+	objURL := &url.URL{Scheme: scheme, Host: u.conf.Endpoint, Path: "/" + u.conf.Bucket + "/" + key}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, objURL.String(), bytes.NewReader(png))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "image/png")
+	if u.conf.AccessKey.String() != "" {
+		signS3Request(req, u.conf, png)
+	}
+
+	resp, err := u.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return "", errors.Errorf("uploading %q: unexpected status %d", key, resp.StatusCode)
+	}
+	return objURL.String(), nil
+}
@@ -0,0 +1,105 @@
+// Copyright 2015 Prometheus Team
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestNoopUploaderUpload(t *testing.T) {
+	var u noopUploader
+	url, err := u.Upload(context.Background(), "key", []byte("png"))
+	if err != nil || url != "" {
+		t.Fatalf("noopUploader.Upload() = (%q, %v), want (\"\", nil)", url, err)
+	}
+}
+
+// TestS3UploaderSignsWithSigV4 checks that Upload authenticates with a
+// SigV4 Authorization header rather than HTTP Basic Auth, since real S3
+// (and S3-compatible stores) reject Basic Auth outright.
+func TestS3UploaderSignsWithSigV4(t *testing.T) {
+	var gotAuth string
+	var gotBasicAuth bool
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotAuth = r.Header.Get("Authorization")
+		_, _, gotBasicAuth = r.BasicAuth()
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	conf := &S3Config{
+		Endpoint:  strings.TrimPrefix(srv.URL, "http://"),
+		Region:    "us-east-1",
+		Bucket:    "bucket",
+		AccessKey: NewSecret("AKIDEXAMPLE"),
+		SecretKey: NewSecret("wJalrXUtnFEMI/K7MDENG/bPxRfiCYEXAMPLEKEY"),
+		Insecure:  true,
+	}
+	u := NewS3Uploader(conf)
+
+	gotURL, err := u.Upload(context.Background(), "alerts/1.png", []byte("fake-png-bytes"))
+	if err != nil {
+		t.Fatalf("Upload: %v", err)
+	}
+	if gotURL == "" {
+		t.Fatalf("Upload returned empty URL")
+	}
+	if gotBasicAuth {
+		t.Fatalf("request authenticated with HTTP Basic Auth instead of SigV4")
+	}
+	if want := "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/"; !strings.HasPrefix(gotAuth, want) {
+		t.Fatalf("Authorization header = %q, want prefix %q", gotAuth, want)
+	}
+}
+
+// TestS3UploaderEscapesSpecialCharactersInKey checks that a key containing
+// "#", "?" or a space reaches the server as the literal object key rather
+// than being parsed as a URL fragment/query, which would otherwise silently
+// truncate the request path and PUT to the wrong object.
+func TestS3UploaderEscapesSpecialCharactersInKey(t *testing.T) {
+	var gotPath string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	conf := &S3Config{
+		Endpoint:  strings.TrimPrefix(srv.URL, "http://"),
+		Region:    "us-east-1",
+		Bucket:    "bucket",
+		AccessKey: NewSecret("AKIDEXAMPLE"),
+		SecretKey: NewSecret("wJalrXUtnFEMI/K7MDENG/bPxRfiCYEXAMPLEKEY"),
+		Insecure:  true,
+	}
+	u := NewS3Uploader(conf)
+
+	const key = "alerts/a#b c?d.png"
+	gotURL, err := u.Upload(context.Background(), key, []byte("fake-png-bytes"))
+	if err != nil {
+		t.Fatalf("Upload: %v", err)
+	}
+
+	want := "/bucket/" + key
+	if gotPath != want {
+		t.Fatalf("server saw request path %q, want %q (key was truncated/mis-parsed)", gotPath, want)
+	}
+	if !strings.Contains(gotURL, "%23") || !strings.Contains(gotURL, "%3F") {
+		t.Fatalf("Upload() URL = %q, want percent-escaped # and ?", gotURL)
+	}
+}
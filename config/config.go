@@ -19,7 +19,9 @@ import (
 	"io/ioutil"
 	"net"
 	"net/url"
+	"os"
 	"path/filepath"
+	"reflect"
 	"regexp"
 	"strings"
 	"time"
@@ -28,6 +30,8 @@ import (
 	commoncfg "github.com/prometheus/common/config"
 	"github.com/prometheus/common/model"
 	"gopkg.in/yaml.v2"
+
+	"github.com/codecomment/alertmanager-with-comment/types"
 )
 
 const secretToken = "<secret>"
@@ -40,27 +44,81 @@ func init() {
 		panic(err)
 	}
 	secretTokenJSON = string(b)
+
+	// AMTOOL_UNSAFE_MARSHAL lets amtool (and similar config-generator tools)
+	// opt into plaintext marshaling for an entire process without every
+	// caller having to know to set MarshalSecretValue itself.
+	if os.Getenv("AMTOOL_UNSAFE_MARSHAL") != "" {
+		MarshalSecretValue = true
+	}
+}
+
+// MarshalSecretValue, when true, makes every Secret and SecretURL marshal to
+// its real value instead of the redacted `<secret>` token, regardless of the
+// per-value unsafe flag. It exists for tools (e.g. a config-generator that
+// re-reads its own output) that need a single call to materialize a whole
+// config; prefer NewUnsafeSecret/NewUnsafeSecretURL for finer-grained control.
+// It defaults to true if the AMTOOL_UNSAFE_MARSHAL environment variable is
+// set to any non-empty value.
+var MarshalSecretValue = false
+
+// Secret is a string that must not be revealed on marshaling unless it was
+// explicitly constructed via NewUnsafeSecret, or MarshalSecretValue is set.
+type Secret struct {
+	value  string
+	unsafe bool
+}
+
+// NewSecret returns a Secret that marshals to the redacted `<secret>` token.
+func NewSecret(s string) Secret {
+	return Secret{value: s}
+}
+
+// NewUnsafeSecret returns a Secret that marshals to its real value.
+func NewUnsafeSecret(s string) Secret {
+	return Secret{value: s, unsafe: true}
 }
 
-// Secret is a string that must not be revealed on marshaling.
-type Secret string
+// IsZero implements yaml.IsZeroer for Secret. Both of Secret's fields are
+// unexported, so yaml.v2's generic struct zero-check (which skips private
+// fields) would otherwise always consider it zero and silently drop any
+// `omitempty` Secret field regardless of content; this makes `omitempty`
+// key off the actual value instead.
+func (s Secret) IsZero() bool {
+	return s.value == ""
+}
+
+// String returns the underlying secret value.
+func (s Secret) String() string {
+	return s.value
+}
 
 // MarshalYAML implements the yaml.Marshaler interface for Secret.
 func (s Secret) MarshalYAML() (interface{}, error) {
-	if s != "" {
-		return secretToken, nil
+	if s.value == "" {
+		return nil, nil
 	}
-	return nil, nil
+	if s.unsafe || MarshalSecretValue {
+		return s.value, nil
+	}
+	return secretToken, nil
 }
 
 // UnmarshalYAML implements the yaml.Unmarshaler interface for Secret.
 func (s *Secret) UnmarshalYAML(unmarshal func(interface{}) error) error {
-	type plain Secret
-	return unmarshal((*plain)(s))
+	var str string
+	if err := unmarshal(&str); err != nil {
+		return err
+	}
+	s.value = str
+	return nil
 }
 
 // MarshalJSON implements the json.Marshaler interface for Secret.
 func (s Secret) MarshalJSON() ([]byte, error) {
+	if s.value != "" && (s.unsafe || MarshalSecretValue) {
+		return json.Marshal(s.value)
+	}
 	return json.Marshal(secretToken)
 }
 
@@ -119,15 +177,59 @@ func (u *URL) UnmarshalJSON(data []byte) error {
 	return nil
 }
 
-// SecretURL is a URL that must not be revealed on marshaling.
-type SecretURL URL
+// SecretURL is a URL that must not be revealed on marshaling unless it was
+// explicitly constructed via NewUnsafeSecretURL, or MarshalSecretValue is set.
+type SecretURL struct {
+	url    *URL
+	unsafe bool
+}
+
+// NewSecretURL returns a SecretURL that marshals to the redacted `<secret>` token.
+func NewSecretURL(u *URL) SecretURL {
+	return SecretURL{url: u}
+}
+
+// NewUnsafeSecretURL returns a SecretURL that marshals to its real value.
+func NewUnsafeSecretURL(u *URL) SecretURL {
+	return SecretURL{url: u, unsafe: true}
+}
+
+// IsZero implements yaml.IsZeroer for SecretURL, for the same reason as
+// Secret.IsZero: both of its fields are unexported, so yaml.v2's generic
+// zero-check would otherwise always treat it as zero.
+func (s SecretURL) IsZero() bool {
+	return s.url == nil || s.url.URL == nil
+}
+
+// String returns the underlying URL, or the empty string if unset.
+func (s SecretURL) String() string {
+	if s.url == nil || s.url.URL == nil {
+		return ""
+	}
+	return s.url.String()
+}
+
+// Copy makes a deep-copy of the struct.
+func (s *SecretURL) Copy() *SecretURL {
+	if s == nil {
+		return nil
+	}
+	cp := *s
+	if s.url != nil {
+		cp.url = s.url.Copy()
+	}
+	return &cp
+}
 
 // MarshalYAML implements the yaml.Marshaler interface for SecretURL.
 func (s SecretURL) MarshalYAML() (interface{}, error) {
-	if s.URL != nil {
-		return secretToken, nil
+	if s.url == nil || s.url.URL == nil {
+		return nil, nil
 	}
-	return nil, nil
+	if s.unsafe || MarshalSecretValue {
+		return s.url.String(), nil
+	}
+	return secretToken, nil
 }
 
 // UnmarshalYAML implements the yaml.Unmarshaler interface for SecretURL.
@@ -140,14 +242,25 @@ func (s *SecretURL) UnmarshalYAML(unmarshal func(interface{}) error) error {
 	// the Alertmanager API with amtool), `<secret>` needs to be treated
 	// specially, as it isn't a valid URL.
 	if str == secretToken {
-		s.URL = &url.URL{}
+		s.url = &URL{&url.URL{}}
 		return nil
 	}
-	return unmarshal((*URL)(s))
+	u, err := parseURL(str)
+	if err != nil {
+		return err
+	}
+	s.url = u
+	return nil
 }
 
 // MarshalJSON implements the json.Marshaler interface for SecretURL.
 func (s SecretURL) MarshalJSON() ([]byte, error) {
+	if s.url == nil || s.url.URL == nil {
+		return []byte("null"), nil
+	}
+	if s.unsafe || MarshalSecretValue {
+		return json.Marshal(s.url.String())
+	}
 	return json.Marshal(secretToken)
 }
 
@@ -157,10 +270,19 @@ func (s *SecretURL) UnmarshalJSON(data []byte) error {
 	// the Alertmanager API with amtool), `<secret>` needs to be treated
 	// specially, as it isn't a valid URL.
 	if string(data) == secretToken || string(data) == secretTokenJSON {
-		s.URL = &url.URL{}
+		s.url = &URL{&url.URL{}}
 		return nil
 	}
-	return json.Unmarshal(data, (*URL)(s))
+	var str string
+	if err := json.Unmarshal(data, &str); err != nil {
+		return err
+	}
+	u, err := parseURL(str)
+	if err != nil {
+		return err
+	}
+	s.url = u
+	return nil
 }
 
 // Load parses the YAML input s into a Config.
@@ -207,6 +329,12 @@ func LoadFile(filename string) (*Config, error) {
 		return nil, err
 	}
 
+	if cfg.S3 != nil {
+		if err := cfg.RefreshTemplates(); err != nil {
+			return nil, err
+		}
+	}
+
 	resolveFilepaths(filepath.Dir(filename), cfg)
 	return cfg, nil
 }
@@ -238,8 +366,18 @@ type Config struct {
 	InhibitRules []*InhibitRule `yaml:"inhibit_rules,omitempty" json:"inhibit_rules,omitempty"`
 	// 接收人规则，接收人的名字和及其具体的通讯方式和细节
 	Receivers []*Receiver `yaml:"receivers,omitempty" json:"receivers,omitempty"`
-	// 模板文件地址，支持使用匹配文件名。如 'templates/*.tmpl'.
+	// 模板文件地址，支持使用匹配文件名。如 'templates/*.tmpl'。也可以是
+	// 's3://bucket/key' 这样的对象存储地址，加载时会被下载到本地缓存目录
+	// 并替换成本地路径，详见S3Config。
 	Templates []string `yaml:"templates" json:"templates"`
+	// S3 可选的对象存储模板源，用于集中管理模板而不必把它们打包进镜像。
+	S3 *S3Config `yaml:"s3,omitempty" json:"s3,omitempty"`
+	// TimeIntervals 命名的时间窗口集合，供Route的mute_time_intervals和
+	// active_time_intervals按名字引用，实现维护窗口（静默期/生效期）。
+	TimeIntervals []*TimeInterval `yaml:"time_intervals,omitempty" json:"time_intervals,omitempty"`
+	// RelabelConfigs 全局relabel规则，在分发到路由树之前对每条告警的label
+	// 集合生效；Route.RelabelConfigs可以针对单个路由追加更多规则。
+	RelabelConfigs []*RelabelConfig `yaml:"relabel_configs,omitempty" json:"relabel_configs,omitempty"`
 
 	// original is the input from which the config was parsed.
 	// ---------------------------------------------------------
@@ -255,6 +393,99 @@ func (c Config) String() string {
 	return string(b)
 }
 
+// MarshalUnsafe marshals the config to YAML with all Secret and SecretURL
+// values materialized to their real value rather than redacted, so that
+// Load(cfg.MarshalUnsafe()) round-trips to an equal Config. Useful for
+// controllers that persist the live config and reload it later.
+//
+// It works on a reflective deep copy of c with every Secret/SecretURL's
+// per-value unsafe flag forced on, rather than flipping the package-level
+// MarshalSecretValue for the duration of the call: the latter is global
+// mutable state, so a concurrent String()/yaml.Marshal of any Config on
+// another goroutine could observe MarshalSecretValue mid-flight and leak
+// real secret values, or race on the flag itself.
+func (c Config) MarshalUnsafe() (string, error) {
+	unsafeCopy := unsafeSecretCopy(reflect.ValueOf(c)).Interface().(Config)
+
+	b, err := yaml.Marshal(unsafeCopy)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+// unsafeSecretCopy returns a deep copy of v with every nested Secret and
+// SecretURL value's unsafe flag set to true, leaving v itself untouched.
+// Unexported fields are copied verbatim (Go struct assignment already
+// copies them) and never walked into directly, since reflect cannot set a
+// field obtained from an unexported field anyway, and MarshalUnsafe only
+// needs the exported, YAML-visible fields patched.
+func unsafeSecretCopy(v reflect.Value) reflect.Value {
+	if !v.IsValid() {
+		return v
+	}
+
+	switch v.Type() {
+	case secretType:
+		s := v.Interface().(Secret)
+		s.unsafe = true
+		return reflect.ValueOf(s)
+	case secretURLType:
+		s := v.Interface().(SecretURL)
+		s.unsafe = true
+		return reflect.ValueOf(s)
+	}
+
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			return v
+		}
+		out := reflect.New(v.Type().Elem())
+		out.Elem().Set(unsafeSecretCopy(v.Elem()))
+		return out
+	case reflect.Slice:
+		if v.IsNil() {
+			return v
+		}
+		out := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		for i := 0; i < v.Len(); i++ {
+			out.Index(i).Set(unsafeSecretCopy(v.Index(i)))
+		}
+		return out
+	case reflect.Map:
+		if v.IsNil() {
+			return v
+		}
+		out := reflect.MakeMapWithSize(v.Type(), v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			out.SetMapIndex(iter.Key(), unsafeSecretCopy(iter.Value()))
+		}
+		return out
+	case reflect.Struct:
+		// Start from a full value copy, which preserves unexported fields
+		// (e.g. Config.original, Regexp's compiled pattern), then patch
+		// exported fields that might contain a Secret/SecretURL.
+		out := reflect.New(v.Type()).Elem()
+		out.Set(v)
+		for i := 0; i < v.NumField(); i++ {
+			if v.Type().Field(i).PkgPath != "" {
+				continue
+			}
+			out.Field(i).Set(unsafeSecretCopy(v.Field(i)))
+		}
+		return out
+	case reflect.Interface:
+		if v.IsNil() {
+			return v
+		}
+		return unsafeSecretCopy(v.Elem())
+	default:
+		return v
+	}
+}
+
 // UnmarshalYAML implements the yaml.Unmarshaler interface for Config.
 func (c *Config) UnmarshalYAML(unmarshal func(interface{}) error) error {
 	// We want to set c to the defaults and then overwrite it with the input.
@@ -302,10 +533,10 @@ func (c *Config) UnmarshalYAML(unmarshal func(interface{}) error) error {
 			if ec.AuthUsername == "" {
 				ec.AuthUsername = c.Global.SMTPAuthUsername
 			}
-			if ec.AuthPassword == "" {
+			if ec.AuthPassword.String() == "" {
 				ec.AuthPassword = c.Global.SMTPAuthPassword
 			}
-			if ec.AuthSecret == "" {
+			if ec.AuthSecret.String() == "" {
 				ec.AuthSecret = c.Global.SMTPAuthSecret
 			}
 			if ec.AuthIdentity == "" {
@@ -356,8 +587,8 @@ func (c *Config) UnmarshalYAML(unmarshal func(interface{}) error) error {
 			if !strings.HasSuffix(ogc.APIURL.Path, "/") {
 				ogc.APIURL.Path += "/"
 			}
-			if ogc.APIKey == "" {
-				if c.Global.OpsGenieAPIKey == "" {
+			if ogc.APIKey.String() == "" {
+				if c.Global.OpsGenieAPIKey.String() == "" {
 					return fmt.Errorf("no global OpsGenie API Key set")
 				}
 				ogc.APIKey = c.Global.OpsGenieAPIKey
@@ -375,8 +606,8 @@ func (c *Config) UnmarshalYAML(unmarshal func(interface{}) error) error {
 				wcc.APIURL = c.Global.WeChatAPIURL
 			}
 
-			if wcc.APISecret == "" {
-				if c.Global.WeChatAPISecret == "" {
+			if wcc.APISecret.String() == "" {
+				if c.Global.WeChatAPISecret.String() == "" {
 					return fmt.Errorf("no global Wechat ApiSecret set")
 				}
 				wcc.APISecret = c.Global.WeChatAPISecret
@@ -406,13 +637,26 @@ func (c *Config) UnmarshalYAML(unmarshal func(interface{}) error) error {
 			if !strings.HasSuffix(voc.APIURL.Path, "/") {
 				voc.APIURL.Path += "/"
 			}
-			if voc.APIKey == "" {
-				if c.Global.VictorOpsAPIKey == "" {
+			if voc.APIKey.String() == "" {
+				if c.Global.VictorOpsAPIKey.String() == "" {
 					return fmt.Errorf("no global VictorOps API Key set")
 				}
 				voc.APIKey = c.Global.VictorOpsAPIKey
 			}
 		}
+		for _, dc := range rcv.DiscordConfigs {
+			if dc.HTTPConfig == nil {
+				dc.HTTPConfig = c.Global.HTTPConfig
+			}
+		}
+		for _, ddc := range rcv.DingtalkConfigs {
+			if ddc.HTTPConfig == nil {
+				ddc.HTTPConfig = c.Global.HTTPConfig
+			}
+			if ddc.Secret.String() == "" {
+				ddc.Secret = c.Global.DingtalkAPISecret
+			}
+		}
 		names[rcv.Name] = struct{}{}
 	}
 
@@ -424,12 +668,26 @@ func (c *Config) UnmarshalYAML(unmarshal func(interface{}) error) error {
 	if len(c.Route.Receiver) == 0 {
 		return fmt.Errorf("root route must specify a default receiver")
 	}
-	if len(c.Route.Match) > 0 || len(c.Route.MatchRE) > 0 {
+	if len(c.Route.Match) > 0 || len(c.Route.MatchRE) > 0 || len(c.Route.MatchGlob) > 0 {
 		return fmt.Errorf("root route must not have any matchers")
 	}
 
 	// Validate that all receivers used in the routing tree are defined.
-	return checkReceiver(c.Route, names)
+	if err := checkReceiver(c.Route, names); err != nil {
+		return err
+	}
+
+	intervals := map[string]*TimeInterval{}
+	for _, ti := range c.TimeIntervals {
+		if _, ok := intervals[ti.Name]; ok {
+			return fmt.Errorf("time interval %q is not unique", ti.Name)
+		}
+		intervals[ti.Name] = ti
+	}
+
+	// Resolve and validate the mute/active time interval names used in the
+	// routing tree, mirroring checkReceiver above.
+	return resolveTimeIntervals(c.Route, intervals)
 }
 
 // checkReceiver returns an error if a node in the routing tree
@@ -449,6 +707,33 @@ func checkReceiver(r *Route, receivers map[string]struct{}) error {
 	return nil
 }
 
+// resolveTimeIntervals validates that every name in r.MuteTimeIntervals and
+// r.ActiveTimeIntervals is defined in intervals, resolves them to the
+// matching *TimeInterval so Route.ShouldMute can evaluate them without a
+// second lookup, and recurses into child routes.
+func resolveTimeIntervals(r *Route, intervals map[string]*TimeInterval) error {
+	for _, name := range r.MuteTimeIntervals {
+		ti, ok := intervals[name]
+		if !ok {
+			return fmt.Errorf("undefined time interval %q used in mute_time_intervals", name)
+		}
+		r.muteTimeIntervals = append(r.muteTimeIntervals, ti)
+	}
+	for _, name := range r.ActiveTimeIntervals {
+		ti, ok := intervals[name]
+		if !ok {
+			return fmt.Errorf("undefined time interval %q used in active_time_intervals", name)
+		}
+		r.activeTimeIntervals = append(r.activeTimeIntervals, ti)
+	}
+	for _, sr := range r.Routes {
+		if err := resolveTimeIntervals(sr, intervals); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // DefaultGlobalConfig returns GlobalConfig with default values.
 func DefaultGlobalConfig() GlobalConfig {
 	return GlobalConfig{
@@ -564,23 +849,25 @@ type GlobalConfig struct {
 
 	HTTPConfig *commoncfg.HTTPClientConfig `yaml:"http_config,omitempty" json:"http_config,omitempty"`
 
-	SMTPFrom         string     `yaml:"smtp_from,omitempty" json:"smtp_from,omitempty"`
-	SMTPHello        string     `yaml:"smtp_hello,omitempty" json:"smtp_hello,omitempty"`
-	SMTPSmarthost    HostPort   `yaml:"smtp_smarthost,omitempty" json:"smtp_smarthost,omitempty"`
-	SMTPAuthUsername string     `yaml:"smtp_auth_username,omitempty" json:"smtp_auth_username,omitempty"`
-	SMTPAuthPassword Secret     `yaml:"smtp_auth_password,omitempty" json:"smtp_auth_password,omitempty"`
-	SMTPAuthSecret   Secret     `yaml:"smtp_auth_secret,omitempty" json:"smtp_auth_secret,omitempty"`
-	SMTPAuthIdentity string     `yaml:"smtp_auth_identity,omitempty" json:"smtp_auth_identity,omitempty"`
-	SMTPRequireTLS   bool       `yaml:"smtp_require_tls,omitempty" json:"smtp_require_tls,omitempty"`
-	SlackAPIURL      *SecretURL `yaml:"slack_api_url,omitempty" json:"slack_api_url,omitempty"`
-	PagerdutyURL     *URL       `yaml:"pagerduty_url,omitempty" json:"pagerduty_url,omitempty"`
-	OpsGenieAPIURL   *URL       `yaml:"opsgenie_api_url,omitempty" json:"opsgenie_api_url,omitempty"`
-	OpsGenieAPIKey   Secret     `yaml:"opsgenie_api_key,omitempty" json:"opsgenie_api_key,omitempty"`
-	WeChatAPIURL     *URL       `yaml:"wechat_api_url,omitempty" json:"wechat_api_url,omitempty"`
-	WeChatAPISecret  Secret     `yaml:"wechat_api_secret,omitempty" json:"wechat_api_secret,omitempty"`
-	WeChatAPICorpID  string     `yaml:"wechat_api_corp_id,omitempty" json:"wechat_api_corp_id,omitempty"`
-	VictorOpsAPIURL  *URL       `yaml:"victorops_api_url,omitempty" json:"victorops_api_url,omitempty"`
-	VictorOpsAPIKey  Secret     `yaml:"victorops_api_key,omitempty" json:"victorops_api_key,omitempty"`
+	SMTPFrom          string     `yaml:"smtp_from,omitempty" json:"smtp_from,omitempty"`
+	SMTPHello         string     `yaml:"smtp_hello,omitempty" json:"smtp_hello,omitempty"`
+	SMTPSmarthost     HostPort   `yaml:"smtp_smarthost,omitempty" json:"smtp_smarthost,omitempty"`
+	SMTPAuthUsername  string     `yaml:"smtp_auth_username,omitempty" json:"smtp_auth_username,omitempty"`
+	SMTPAuthPassword  Secret     `yaml:"smtp_auth_password,omitempty" json:"smtp_auth_password,omitempty"`
+	SMTPAuthSecret    Secret     `yaml:"smtp_auth_secret,omitempty" json:"smtp_auth_secret,omitempty"`
+	SMTPAuthIdentity  string     `yaml:"smtp_auth_identity,omitempty" json:"smtp_auth_identity,omitempty"`
+	SMTPRequireTLS    bool       `yaml:"smtp_require_tls,omitempty" json:"smtp_require_tls,omitempty"`
+	SlackAPIURL       *SecretURL `yaml:"slack_api_url,omitempty" json:"slack_api_url,omitempty"`
+	PagerdutyURL      *URL       `yaml:"pagerduty_url,omitempty" json:"pagerduty_url,omitempty"`
+	OpsGenieAPIURL    *URL       `yaml:"opsgenie_api_url,omitempty" json:"opsgenie_api_url,omitempty"`
+	OpsGenieAPIKey    Secret     `yaml:"opsgenie_api_key,omitempty" json:"opsgenie_api_key,omitempty"`
+	WeChatAPIURL      *URL       `yaml:"wechat_api_url,omitempty" json:"wechat_api_url,omitempty"`
+	WeChatAPISecret   Secret     `yaml:"wechat_api_secret,omitempty" json:"wechat_api_secret,omitempty"`
+	WeChatAPICorpID   string     `yaml:"wechat_api_corp_id,omitempty" json:"wechat_api_corp_id,omitempty"`
+	VictorOpsAPIURL   *URL       `yaml:"victorops_api_url,omitempty" json:"victorops_api_url,omitempty"`
+	VictorOpsAPIKey   Secret     `yaml:"victorops_api_key,omitempty" json:"victorops_api_key,omitempty"`
+	DingtalkAPIURL    *URL       `yaml:"dingtalk_api_url,omitempty" json:"dingtalk_api_url,omitempty"`
+	DingtalkAPISecret Secret     `yaml:"dingtalk_api_secret,omitempty" json:"dingtalk_api_secret,omitempty"`
 }
 
 // UnmarshalYAML implements the yaml.Unmarshaler interface for GlobalConfig.
@@ -618,6 +905,13 @@ type Route struct {
 	Match map[string]string `yaml:"match,omitempty" json:"match,omitempty"`
 	// MatchRE 支持正则表达式去匹配。
 	MatchRE MatchRegexps `yaml:"match_re,omitempty" json:"match_re,omitempty"`
+	// MatchGlob is like Match, but values are shell-style glob patterns
+	// (see types.MatchGlob) rather than exact strings -- a cheaper
+	// alternative to MatchRE for the common "prefix*" case.
+	// -------------------------------------------------------------------
+	// MatchGlob 和Match类似，但值是shell风格的通配符模式（参见
+	// types.MatchGlob），对于"prefix*"这类常见场景，比MatchRE更省开销。
+	MatchGlob map[string]string `yaml:"match_glob,omitempty" json:"match_glob,omitempty"`
 	// Continue 匹配中之后，是否在继续匹配其兄弟节点。
 	Continue bool `yaml:"continue,omitempty" json:"continue,omitempty"`
 	// Routes 当前节点的子节点
@@ -630,6 +924,45 @@ type Route struct {
 	GroupInterval *model.Duration `yaml:"group_interval,omitempty" json:"group_interval,omitempty"`
 	// 重复间隔，当告警匹配到这个分组后，当告警发送过之后，需要等待重复间隔时间，其告警才会被再次发送。
 	RepeatInterval *model.Duration `yaml:"repeat_interval,omitempty" json:"repeat_interval,omitempty"`
+
+	// MuteTimeIntervals 维护窗口名称列表，引用Config.TimeIntervals。当前时间
+	// 落在其中任意一个时间窗口内时，这个节点匹配到的告警会被静默（不发送）。
+	MuteTimeIntervals []string `yaml:"mute_time_intervals,omitempty" json:"mute_time_intervals,omitempty"`
+	// ActiveTimeIntervals 生效窗口名称列表，引用Config.TimeIntervals。配置后，
+	// 只有当前时间落在其中任意一个时间窗口内时，告警才会被发送；为空则表示一直生效。
+	ActiveTimeIntervals []string `yaml:"active_time_intervals,omitempty" json:"active_time_intervals,omitempty"`
+
+	// RelabelConfigs relabel规则，在告警匹配到这个节点之后，匹配其子节点之前生效，
+	// 可用于在路由树内部按路径逐级改写/丢弃/规范化label。
+	RelabelConfigs []*RelabelConfig `yaml:"relabel_configs,omitempty" json:"relabel_configs,omitempty"`
+
+	// muteTimeIntervals and activeTimeIntervals are resolved from
+	// MuteTimeIntervals/ActiveTimeIntervals by Config.UnmarshalYAML once the
+	// full set of named TimeIntervals is known. They back ShouldMute.
+	muteTimeIntervals   []*TimeInterval
+	activeTimeIntervals []*TimeInterval
+}
+
+// ShouldMute reports whether alerts matching this route should be
+// suppressed at time now, based on its mute_time_intervals and
+// active_time_intervals. A route is muted if now falls within any of its
+// mute windows, or if active windows are configured and now falls outside
+// all of them.
+func (r *Route) ShouldMute(now time.Time) bool {
+	for _, ti := range r.muteTimeIntervals {
+		if ti.ContainsTime(now) {
+			return true
+		}
+	}
+	if len(r.activeTimeIntervals) == 0 {
+		return false
+	}
+	for _, ti := range r.activeTimeIntervals {
+		if ti.ContainsTime(now) {
+			return false
+		}
+	}
+	return true
 }
 
 // UnmarshalYAML implements the yaml.Unmarshaler interface for Route.
@@ -645,6 +978,15 @@ func (r *Route) UnmarshalYAML(unmarshal func(interface{}) error) error {
 		}
 	}
 
+	for k, v := range r.MatchGlob {
+		if !model.LabelNameRE.MatchString(k) {
+			return fmt.Errorf("invalid label name %q", k)
+		}
+		if err := types.ValidateGlobPattern(v); err != nil {
+			return fmt.Errorf("invalid match_glob pattern for %q: %w", k, err)
+		}
+	}
+
 	for _, l := range r.GroupByStr {
 		if l == "..." {
 			r.GroupByAll = true
@@ -677,6 +1019,16 @@ func (r *Route) UnmarshalYAML(unmarshal func(interface{}) error) error {
 		return fmt.Errorf("repeat_interval cannot be zero")
 	}
 
+	active := map[string]struct{}{}
+	for _, name := range r.ActiveTimeIntervals {
+		active[name] = struct{}{}
+	}
+	for _, name := range r.MuteTimeIntervals {
+		if _, ok := active[name]; ok {
+			return fmt.Errorf("time interval %q cannot be both a mute_time_interval and an active_time_interval on the same route", name)
+		}
+	}
+
 	return nil
 }
 
@@ -688,17 +1040,21 @@ func (r *Route) UnmarshalYAML(unmarshal func(interface{}) error) error {
 // label的告警。target告警和source告警必须有一组相同的label。
 // Ex:
 // source match:
-//   level: error
+//
+//	level: error
+//
 // target match:
-//   level: info
+//
+//	level: info
+//
 // equal:
-//   env
+//
+//	env
 //
 // alert1<level=info, env=debug>
 // alert2<level=error, env=debug>
 //
 // 在这个配置下，alert2发生后，会抑制alert1。
-//
 type InhibitRule struct {
 	// SourceMatch defines a set of labels that have to equal the given
 	// value for source alerts.
@@ -712,6 +1068,13 @@ type InhibitRule struct {
 	// SourceMatchRE 和SourceMatch相似，负责正则匹配。
 	SourceMatchRE MatchRegexps `yaml:"source_match_re,omitempty" json:"source_match_re,omitempty"`
 
+	// SourceMatchGlob defines pairs like SourceMatch but values are
+	// shell-style glob patterns (see types.MatchGlob).
+	// -------------------------------------------------------------------
+	// SourceMatchGlob 和SourceMatch相似，但值是shell风格的通配符模式
+	// （参见types.MatchGlob）。
+	SourceMatchGlob map[string]string `yaml:"source_match_glob,omitempty" json:"source_match_glob,omitempty"`
+
 	// TargetMatch defines a set of labels that have to equal the given
 	// value for target alerts.
 	// -------------------------------------------------------------------
@@ -724,6 +1087,13 @@ type InhibitRule struct {
 	// TargetMatchRE 和TargetMatch相似，负责正则匹配。
 	TargetMatchRE MatchRegexps `yaml:"target_match_re,omitempty" json:"target_match_re,omitempty"`
 
+	// TargetMatchGlob defines pairs like TargetMatch but values are
+	// shell-style glob patterns (see types.MatchGlob).
+	// -------------------------------------------------------------------
+	// TargetMatchGlob 和TargetMatch相似，但值是shell风格的通配符模式
+	// （参见types.MatchGlob）。
+	TargetMatchGlob map[string]string `yaml:"target_match_glob,omitempty" json:"target_match_glob,omitempty"`
+
 	// A set of labels that must be equal between the source and target alert
 	// for them to be a match.
 	// -------------------------------------------------------------------
@@ -744,12 +1114,30 @@ func (r *InhibitRule) UnmarshalYAML(unmarshal func(interface{}) error) error {
 		}
 	}
 
+	for k, v := range r.SourceMatchGlob {
+		if !model.LabelNameRE.MatchString(k) {
+			return fmt.Errorf("invalid label name %q", k)
+		}
+		if err := types.ValidateGlobPattern(v); err != nil {
+			return fmt.Errorf("invalid source_match_glob pattern for %q: %w", k, err)
+		}
+	}
+
 	for k := range r.TargetMatch {
 		if !model.LabelNameRE.MatchString(k) {
 			return fmt.Errorf("invalid label name %q", k)
 		}
 	}
 
+	for k, v := range r.TargetMatchGlob {
+		if !model.LabelNameRE.MatchString(k) {
+			return fmt.Errorf("invalid label name %q", k)
+		}
+		if err := types.ValidateGlobPattern(v); err != nil {
+			return fmt.Errorf("invalid target_match_glob pattern for %q: %w", k, err)
+		}
+	}
+
 	return nil
 }
 
@@ -769,6 +1157,8 @@ type Receiver struct {
 	WechatConfigs    []*WechatConfig    `yaml:"wechat_configs,omitempty" json:"wechat_configs,omitempty"`
 	PushoverConfigs  []*PushoverConfig  `yaml:"pushover_configs,omitempty" json:"pushover_configs,omitempty"`
 	VictorOpsConfigs []*VictorOpsConfig `yaml:"victorops_configs,omitempty" json:"victorops_configs,omitempty"`
+	DiscordConfigs   []*DiscordConfig   `yaml:"discord_configs,omitempty" json:"discord_configs,omitempty"`
+	DingtalkConfigs  []*DingtalkConfig  `yaml:"dingtalk_configs,omitempty" json:"dingtalk_configs,omitempty"`
 }
 
 // UnmarshalYAML implements the yaml.Unmarshaler interface for Receiver.
@@ -783,6 +1173,148 @@ func (c *Receiver) UnmarshalYAML(unmarshal func(interface{}) error) error {
 	return nil
 }
 
+// NotifierConfig contains base options common across all notifier configurations.
+// -------------------------------------------------------------------------------
+// NotifierConfig 是所有渠道通用的基础配置，目前只包含是否在告警恢复时也发送通知。
+type NotifierConfig struct {
+	VSendResolved bool `yaml:"send_resolved" json:"send_resolved"`
+}
+
+// SendResolved returns whether the notifier should notify about resolved alerts.
+func (nc *NotifierConfig) SendResolved() bool {
+	return nc.VSendResolved
+}
+
+const (
+	// DefaultDiscordTitle defines the default Discord embed title template.
+	DefaultDiscordTitle = `{{ template "discord.default.title" . }}`
+	// DefaultDiscordMessage defines the default Discord message content template.
+	DefaultDiscordMessage = `{{ template "discord.default.message" . }}`
+)
+
+// DiscordConfig configures notifications via Discord.
+// -------------------------------------------------------------------------------
+// DiscordConfig 定义Discord渠道的配置，通过webhook url向Discord频道投递消息，
+// 支持自定义机器人的用户名和头像，以及标题和正文模板。
+type DiscordConfig struct {
+	NotifierConfig `yaml:",inline" json:",inline"`
+
+	HTTPConfig *commoncfg.HTTPClientConfig `yaml:"http_config,omitempty" json:"http_config,omitempty"`
+	WebhookURL *SecretURL                  `yaml:"webhook_url,omitempty" json:"webhook_url,omitempty"`
+	Username   string                      `yaml:"username,omitempty" json:"username,omitempty"`
+	AvatarURL  string                      `yaml:"avatar_url,omitempty" json:"avatar_url,omitempty"`
+	Title      string                      `yaml:"title,omitempty" json:"title,omitempty"`
+	Message    string                      `yaml:"message,omitempty" json:"message,omitempty"`
+}
+
+// UnmarshalYAML implements the yaml.Unmarshaler interface for DiscordConfig.
+func (c *DiscordConfig) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	type plain DiscordConfig
+	if err := unmarshal((*plain)(c)); err != nil {
+		return err
+	}
+	if c.WebhookURL == nil {
+		return fmt.Errorf("missing webhook_url in discord_config")
+	}
+	if c.Title == "" {
+		c.Title = DefaultDiscordTitle
+	}
+	if c.Message == "" {
+		c.Message = DefaultDiscordMessage
+	}
+	return nil
+}
+
+const (
+	// DefaultDingtalkTitle defines the default DingTalk markdown title template.
+	DefaultDingtalkTitle = `{{ template "dingtalk.default.title" . }}`
+	// DefaultDingtalkText defines the default DingTalk markdown body template.
+	DefaultDingtalkText = `{{ template "dingtalk.default.content" . }}`
+)
+
+// DingtalkMarkdown holds the markdown title/text templates for a DingtalkConfig.
+type DingtalkMarkdown struct {
+	Title string `yaml:"title,omitempty" json:"title,omitempty"`
+	Text  string `yaml:"text,omitempty" json:"text,omitempty"`
+}
+
+// DingtalkActionCard holds the actionCard title/text/button templates for a
+// DingtalkConfig.
+type DingtalkActionCard struct {
+	Title      string `yaml:"title,omitempty" json:"title,omitempty"`
+	Text       string `yaml:"text,omitempty" json:"text,omitempty"`
+	SingleURL  string `yaml:"single_url,omitempty" json:"single_url,omitempty"`
+	SingleText string `yaml:"single_title,omitempty" json:"single_title,omitempty"`
+}
+
+// DingtalkConfig configures notifications via a DingTalk custom robot webhook.
+// -------------------------------------------------------------------------------
+// DingtalkConfig 定义钉钉自定义机器人渠道的配置。支持markdown、text和
+// actionCard三种消息类型，以及可选的加签密钥和@提醒（at_mobiles、at_all）。
+type DingtalkConfig struct {
+	NotifierConfig `yaml:",inline" json:",inline"`
+
+	HTTPConfig *commoncfg.HTTPClientConfig `yaml:"http_config,omitempty" json:"http_config,omitempty"`
+	WebhookURL *SecretURL                  `yaml:"webhook_url,omitempty" json:"webhook_url,omitempty"`
+	// Secret 是钉钉机器人安全设置中的“加签”密钥，用于计算timestamp+sign。
+	Secret      Secret              `yaml:"secret,omitempty" json:"secret,omitempty"`
+	MessageType string              `yaml:"message_type,omitempty" json:"message_type,omitempty"`
+	Markdown    *DingtalkMarkdown   `yaml:"markdown,omitempty" json:"markdown,omitempty"`
+	ActionCard  *DingtalkActionCard `yaml:"action_card,omitempty" json:"action_card,omitempty"`
+	Text        string              `yaml:"text,omitempty" json:"text,omitempty"`
+	AtMobiles   []string            `yaml:"at_mobiles,omitempty" json:"at_mobiles,omitempty"`
+	AtAll       bool                `yaml:"at_all,omitempty" json:"at_all,omitempty"`
+}
+
+// UnmarshalYAML implements the yaml.Unmarshaler interface for DingtalkConfig.
+func (c *DingtalkConfig) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	type plain DingtalkConfig
+	if err := unmarshal((*plain)(c)); err != nil {
+		return err
+	}
+	if c.WebhookURL == nil {
+		return fmt.Errorf("missing webhook_url in dingtalk_config")
+	}
+	if c.MessageType == "" {
+		c.MessageType = "markdown"
+	}
+	if c.MessageType != "markdown" && c.MessageType != "text" && c.MessageType != "actionCard" {
+		return fmt.Errorf("invalid message_type %q in dingtalk_config, must be markdown, text or actionCard", c.MessageType)
+	}
+	if c.MessageType != "text" && c.Text != "" {
+		return fmt.Errorf("text must only be set when message_type is text in dingtalk_config")
+	}
+	if c.MessageType != "markdown" && c.Markdown != nil {
+		return fmt.Errorf("markdown must only be set when message_type is markdown in dingtalk_config")
+	}
+	if c.MessageType != "actionCard" && c.ActionCard != nil {
+		return fmt.Errorf("action_card must only be set when message_type is actionCard in dingtalk_config")
+	}
+	if c.MessageType == "markdown" {
+		if c.Markdown == nil {
+			c.Markdown = &DingtalkMarkdown{}
+		}
+		if c.Markdown.Title == "" {
+			c.Markdown.Title = DefaultDingtalkTitle
+		}
+		if c.Markdown.Text == "" {
+			c.Markdown.Text = DefaultDingtalkText
+		}
+	}
+	if c.MessageType == "actionCard" {
+		if c.ActionCard == nil {
+			c.ActionCard = &DingtalkActionCard{}
+		}
+		if c.ActionCard.Title == "" {
+			c.ActionCard.Title = DefaultDingtalkTitle
+		}
+		if c.ActionCard.Text == "" {
+			c.ActionCard.Text = DefaultDingtalkText
+		}
+	}
+	return nil
+}
+
 // MatchRegexps represents a map of Regexp.
 type MatchRegexps map[string]Regexp
 
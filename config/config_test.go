@@ -0,0 +1,111 @@
+// Copyright 2015 Prometheus Team
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"reflect"
+	"testing"
+
+	"gopkg.in/yaml.v2"
+)
+
+// TestConfig_UnmarshalThenMarshal feeds minimal YAML snippets through Load
+// and back through yaml.Marshal, asserting the output is structurally equal
+// to the input (key order aside). This guards the omitempty/default-value
+// handling added to RelabelConfig.MarshalYAML: fields left at their
+// DefaultRelabelConfig value must not reappear in the marshaled output.
+func TestConfig_UnmarshalThenMarshal(t *testing.T) {
+	// Every case carries the full default global block and an empty
+	// templates list explicitly, since Load fills those in regardless of
+	// whether the input set them; the point under test is whether
+	// RelabelConfig.MarshalYAML omits fields left at DefaultRelabelConfig,
+	// not whether Config itself omits its own defaults.
+	const defaultGlobal = `global:
+  resolve_timeout: 5m
+  http_config:
+    follow_redirects: false
+    enable_http2: false
+  smtp_hello: localhost
+  smtp_require_tls: true
+  pagerduty_url: https://events.pagerduty.com/v2/enqueue
+  opsgenie_api_url: https://api.opsgenie.com/
+  wechat_api_url: https://qyapi.weixin.qq.com/cgi-bin/
+  victorops_api_url: https://alert.victorops.com/integrations/generic/20131114/alert/
+`
+
+	tests := []struct {
+		name string
+		in   string
+	}{
+		{
+			name: "minimal route and receiver",
+			in: defaultGlobal + `route:
+  receiver: default
+receivers:
+- name: default
+templates: []
+`,
+		},
+		{
+			name: "relabel_configs at all defaults except source_labels and target_label",
+			in: defaultGlobal + `route:
+  receiver: default
+receivers:
+- name: default
+templates: []
+relabel_configs:
+- source_labels: [alertname]
+  target_label: team
+`,
+		},
+		{
+			name: "relabel_configs with explicit non-default values",
+			in: defaultGlobal + `route:
+  receiver: default
+receivers:
+- name: default
+templates: []
+relabel_configs:
+- source_labels: [alertname, severity]
+  separator: ','
+  regex: (.+)-(.+)
+  target_label: team
+  replacement: $2
+  action: keep
+`,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg, err := Load(tc.in)
+			if err != nil {
+				t.Fatalf("Load: %v", err)
+			}
+
+			out := cfg.String()
+
+			var wantGeneric, gotGeneric interface{}
+			if err := yaml.Unmarshal([]byte(tc.in), &wantGeneric); err != nil {
+				t.Fatalf("Unmarshal(in): %v", err)
+			}
+			if err := yaml.Unmarshal([]byte(out), &gotGeneric); err != nil {
+				t.Fatalf("Unmarshal(out): %v", err)
+			}
+			if !reflect.DeepEqual(wantGeneric, gotGeneric) {
+				t.Fatalf("round-trip mismatch:\n--- in ---\n%s\n--- out ---\n%s", tc.in, out)
+			}
+		})
+	}
+}
@@ -0,0 +1,332 @@
+// Copyright 2015 Prometheus Team
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+
+	"github.com/prometheus/common/model"
+)
+
+// Equal reports whether c and other describe the same configuration, and if
+// not, a reason string naming the first field path that diverges (e.g.
+// "route.routes[2].receiver"). Unlike a textual or YAML diff this is
+// resilient to map ordering, default-filled vs. explicit fields, and secret
+// redaction, which makes it suitable for controllers deciding whether a
+// desired config is already live.
+// -------------------------------------------------------------------------
+// Equal 用于语义化地比较两份配置是否一致，而不是简单地比较YAML文本（那样会
+// 被map顺序、默认值填充、secret脱敏等因素干扰）。不相等时，reason会指出
+// 第一个不同的字段路径，方便做配置下发的控制器调试。
+func (c *Config) Equal(other *Config) (bool, string) {
+	if c == nil || other == nil {
+		if c == other {
+			return true, ""
+		}
+		return false, "config"
+	}
+
+	if ok, reason := equalValue(reflect.ValueOf(c.Global), reflect.ValueOf(other.Global)); !ok {
+		return false, "global" + reason
+	}
+	if ok, reason := c.Route.Equal(other.Route); !ok {
+		return false, "route" + reason
+	}
+	if ok, reason := equalValue(reflect.ValueOf(c.InhibitRules), reflect.ValueOf(other.InhibitRules)); !ok {
+		return false, "inhibit_rules" + reason
+	}
+	if ok, reason := equalReceivers(c.Receivers, other.Receivers); !ok {
+		return false, reason
+	}
+	if ok, reason := equalValue(reflect.ValueOf(c.Templates), reflect.ValueOf(other.Templates)); !ok {
+		return false, "templates" + reason
+	}
+	if ok, reason := equalValue(reflect.ValueOf(c.S3), reflect.ValueOf(other.S3)); !ok {
+		return false, "s3" + reason
+	}
+	if ok, reason := equalValue(reflect.ValueOf(c.TimeIntervals), reflect.ValueOf(other.TimeIntervals)); !ok {
+		return false, "time_intervals" + reason
+	}
+	if ok, reason := equalValue(reflect.ValueOf(c.RelabelConfigs), reflect.ValueOf(other.RelabelConfigs)); !ok {
+		return false, "relabel_configs" + reason
+	}
+	return true, ""
+}
+
+// equalReceivers compares two receiver lists by name rather than position,
+// since a reconciling controller may legitimately emit them in a different
+// order than what is currently live.
+func equalReceivers(a, b []*Receiver) (bool, string) {
+	byName := func(rs []*Receiver) map[string]*Receiver {
+		m := make(map[string]*Receiver, len(rs))
+		for _, r := range rs {
+			m[r.Name] = r
+		}
+		return m
+	}
+	am, bm := byName(a), byName(b)
+	if len(am) != len(bm) {
+		return false, fmt.Sprintf("receivers[len %d!=%d]", len(am), len(bm))
+	}
+	for name, ra := range am {
+		rb, ok := bm[name]
+		if !ok {
+			return false, fmt.Sprintf("receivers[%s] missing", name)
+		}
+		if ok, reason := equalValue(reflect.ValueOf(ra), reflect.ValueOf(rb)); !ok {
+			return false, fmt.Sprintf("receivers[%s]%s", name, reason)
+		}
+	}
+	return true, ""
+}
+
+// Equal reports whether r and other describe the same routing (sub)tree,
+// and if not, a reason string naming the first diverging field path rooted
+// at this node (e.g. ".routes[2].receiver").
+func (r *Route) Equal(other *Route) (bool, string) {
+	if r == nil || other == nil {
+		if r == other {
+			return true, ""
+		}
+		return false, ""
+	}
+
+	if r.Receiver != other.Receiver {
+		return false, fmt.Sprintf(".receiver[%q!=%q]", r.Receiver, other.Receiver)
+	}
+	if r.Continue != other.Continue {
+		return false, ".continue"
+	}
+	if r.GroupByAll != other.GroupByAll {
+		return false, ".group_by"
+	}
+	if !equalLabelNameSet(r.GroupBy, other.GroupBy) {
+		return false, ".group_by"
+	}
+	if ok, reason := equalValue(reflect.ValueOf(r.Match), reflect.ValueOf(other.Match)); !ok {
+		return false, ".match" + reason
+	}
+	if ok, reason := equalValue(reflect.ValueOf(r.MatchRE), reflect.ValueOf(other.MatchRE)); !ok {
+		return false, ".match_re" + reason
+	}
+	if ok, reason := equalValue(reflect.ValueOf(r.MatchGlob), reflect.ValueOf(other.MatchGlob)); !ok {
+		return false, ".match_glob" + reason
+	}
+	if ok, reason := equalDuration(r.GroupWait, other.GroupWait); !ok {
+		return false, ".group_wait" + reason
+	}
+	if ok, reason := equalDuration(r.GroupInterval, other.GroupInterval); !ok {
+		return false, ".group_interval" + reason
+	}
+	if ok, reason := equalDuration(r.RepeatInterval, other.RepeatInterval); !ok {
+		return false, ".repeat_interval" + reason
+	}
+	if !equalStringSet(r.MuteTimeIntervals, other.MuteTimeIntervals) {
+		return false, ".mute_time_intervals"
+	}
+	if !equalStringSet(r.ActiveTimeIntervals, other.ActiveTimeIntervals) {
+		return false, ".active_time_intervals"
+	}
+	if ok, reason := equalValue(reflect.ValueOf(r.RelabelConfigs), reflect.ValueOf(other.RelabelConfigs)); !ok {
+		return false, ".relabel_configs" + reason
+	}
+
+	if len(r.Routes) != len(other.Routes) {
+		return false, fmt.Sprintf(".routes[len %d!=%d]", len(r.Routes), len(other.Routes))
+	}
+	for i, sr := range r.Routes {
+		if ok, reason := sr.Equal(other.Routes[i]); !ok {
+			return false, fmt.Sprintf(".routes[%d]%s", i, reason)
+		}
+	}
+	return true, ""
+}
+
+func equalLabelNameSet(a, b []model.LabelName) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	as := append([]model.LabelName{}, a...)
+	bs := append([]model.LabelName{}, b...)
+	sort.Slice(as, func(i, j int) bool { return as[i] < as[j] })
+	sort.Slice(bs, func(i, j int) bool { return bs[i] < bs[j] })
+	for i := range as {
+		if as[i] != bs[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func equalStringSet(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	as := append([]string{}, a...)
+	bs := append([]string{}, b...)
+	sort.Strings(as)
+	sort.Strings(bs)
+	for i := range as {
+		if as[i] != bs[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func equalDuration(a, b *model.Duration) (bool, string) {
+	var av, bv model.Duration
+	if a != nil {
+		av = *a
+	}
+	if b != nil {
+		bv = *b
+	}
+	if av != bv {
+		return false, fmt.Sprintf("[%v!=%v]", av, bv)
+	}
+	return true, ""
+}
+
+var (
+	secretType    = reflect.TypeOf(Secret{})
+	secretURLType = reflect.TypeOf(SecretURL{})
+	regexpType    = reflect.TypeOf(Regexp{})
+)
+
+// equalValue performs a semantic, reflection-based comparison of two
+// arbitrary config values. It treats nil and empty slices/maps as equal,
+// compares Secret/SecretURL by their underlying value rather than their
+// redacted marshaled form, compares Regexp by its source pattern, and
+// otherwise recurses structurally. The returned string is a field-path
+// suffix (e.g. "[2].name") to be appended by the caller, empty when equal.
+//
+// This exists because Receiver embeds notifier config slices (EmailConfigs,
+// SlackConfigs, ...) whose exact shape can evolve independently of this
+// file; walking them via reflection means Equal doesn't need to be updated
+// every time a new notifier config gains a field.
+func equalValue(a, b reflect.Value) (bool, string) {
+	if !a.IsValid() || !b.IsValid() {
+		if a.IsValid() == b.IsValid() {
+			return true, ""
+		}
+		return false, ""
+	}
+	if a.Type() != b.Type() {
+		return false, ""
+	}
+
+	switch a.Type() {
+	case secretType:
+		as := a.Interface().(Secret)
+		bs := b.Interface().(Secret)
+		if as.String() != bs.String() {
+			return false, "[secret mismatch]"
+		}
+		return true, ""
+	case secretURLType:
+		as := a.Interface().(SecretURL)
+		bs := b.Interface().(SecretURL)
+		if as.String() != bs.String() {
+			return false, "[secret url mismatch]"
+		}
+		return true, ""
+	case regexpType:
+		as := a.Interface().(Regexp)
+		bs := b.Interface().(Regexp)
+		aStr, bStr := "", ""
+		if as.Regexp != nil {
+			aStr = as.String()
+		}
+		if bs.Regexp != nil {
+			bStr = bs.String()
+		}
+		if aStr != bStr {
+			return false, fmt.Sprintf("[regex %q!=%q]", aStr, bStr)
+		}
+		return true, ""
+	}
+
+	switch a.Kind() {
+	case reflect.Ptr:
+		if a.IsNil() || b.IsNil() {
+			if a.IsNil() == b.IsNil() {
+				return true, ""
+			}
+			return false, "[nil mismatch]"
+		}
+		return equalValue(a.Elem(), b.Elem())
+
+	case reflect.Slice:
+		if a.Len() != b.Len() {
+			return false, fmt.Sprintf("[len %d!=%d]", a.Len(), b.Len())
+		}
+		for i := 0; i < a.Len(); i++ {
+			if ok, reason := equalValue(a.Index(i), b.Index(i)); !ok {
+				return false, fmt.Sprintf("[%d]%s", i, reason)
+			}
+		}
+		return true, ""
+
+	case reflect.Map:
+		if a.Len() != b.Len() {
+			return false, fmt.Sprintf("[len %d!=%d]", a.Len(), b.Len())
+		}
+		iter := a.MapRange()
+		for iter.Next() {
+			k := iter.Key()
+			bv := b.MapIndex(k)
+			if !bv.IsValid() {
+				return false, fmt.Sprintf("[%v missing]", k.Interface())
+			}
+			if ok, reason := equalValue(iter.Value(), bv); !ok {
+				return false, fmt.Sprintf("[%v]%s", k.Interface(), reason)
+			}
+		}
+		return true, ""
+
+	case reflect.Struct:
+		t := a.Type()
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			if f.PkgPath != "" {
+				// Unexported field (e.g. Config.original); not part of the
+				// semantic configuration.
+				continue
+			}
+			if ok, reason := equalValue(a.Field(i), b.Field(i)); !ok {
+				return false, "." + f.Name + reason
+			}
+		}
+		return true, ""
+
+	case reflect.Interface:
+		if a.IsNil() || b.IsNil() {
+			if a.IsNil() == b.IsNil() {
+				return true, ""
+			}
+			return false, "[nil mismatch]"
+		}
+		return equalValue(a.Elem(), b.Elem())
+
+	default:
+		av, bv := a.Interface(), b.Interface()
+		if !reflect.DeepEqual(av, bv) {
+			return false, fmt.Sprintf("[%v!=%v]", av, bv)
+		}
+		return true, ""
+	}
+}
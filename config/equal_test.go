@@ -0,0 +1,186 @@
+// Copyright 2015 Prometheus Team
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestConfigEqual(t *testing.T) {
+	base := func() *Config {
+		return &Config{
+			Route:     &Route{Receiver: "default"},
+			Templates: []string{},
+		}
+	}
+
+	tests := []struct {
+		name    string
+		mutateA func(c *Config)
+		mutateB func(c *Config)
+		wantEq  bool
+		wantTag string
+	}{
+		{
+			name:    "identical",
+			mutateB: func(c *Config) {},
+			wantEq:  true,
+		},
+		{
+			name: "differing s3 endpoint",
+			mutateB: func(c *Config) {
+				c.S3 = &S3Config{Endpoint: "other.example.com"}
+			},
+			wantEq:  false,
+			wantTag: "s3",
+		},
+		{
+			name: "differing time interval name",
+			mutateB: func(c *Config) {
+				c.TimeIntervals = []*TimeInterval{{Name: "other"}}
+			},
+			wantEq:  false,
+			wantTag: "time_intervals",
+		},
+		{
+			name: "differing relabel config",
+			mutateB: func(c *Config) {
+				c.RelabelConfigs = []*RelabelConfig{{TargetLabel: "other"}}
+			},
+			wantEq:  false,
+			wantTag: "relabel_configs",
+		},
+		{
+			name: "differing route mute_time_intervals",
+			mutateB: func(c *Config) {
+				c.Route.MuteTimeIntervals = []string{"weekends"}
+			},
+			wantEq:  false,
+			wantTag: "route.mute_time_intervals",
+		},
+		{
+			name: "differing route active_time_intervals",
+			mutateB: func(c *Config) {
+				c.Route.ActiveTimeIntervals = []string{"business-hours"}
+			},
+			wantEq:  false,
+			wantTag: "route.active_time_intervals",
+		},
+		{
+			name: "differing route relabel_configs",
+			mutateB: func(c *Config) {
+				c.Route.RelabelConfigs = []*RelabelConfig{{TargetLabel: "team"}}
+			},
+			wantEq:  false,
+			wantTag: "route.relabel_configs",
+		},
+		{
+			name:    "same mute_time_intervals in different order",
+			mutateA: func(c *Config) { c.Route.MuteTimeIntervals = []string{"b", "a"} },
+			mutateB: func(c *Config) { c.Route.MuteTimeIntervals = []string{"a", "b"} },
+			wantEq:  true,
+		},
+		{
+			name: "reordered child routes are unequal",
+			mutateA: func(c *Config) {
+				c.Route.Routes = []*Route{
+					{Receiver: "a"},
+					{Receiver: "b"},
+				}
+			},
+			mutateB: func(c *Config) {
+				c.Route.Routes = []*Route{
+					{Receiver: "b"},
+					{Receiver: "a"},
+				}
+			},
+			wantEq:  false,
+			wantTag: "route.routes[0]",
+		},
+		{
+			name: "differing secret",
+			mutateA: func(c *Config) {
+				c.S3 = &S3Config{Endpoint: "s3.example.com", AccessKey: NewSecret("key-a")}
+			},
+			mutateB: func(c *Config) {
+				c.S3 = &S3Config{Endpoint: "s3.example.com", AccessKey: NewSecret("key-b")}
+			},
+			wantEq:  false,
+			wantTag: "s3",
+		},
+		{
+			name: "same secret value",
+			mutateA: func(c *Config) {
+				c.S3 = &S3Config{Endpoint: "s3.example.com", AccessKey: NewSecret("key-a")}
+			},
+			mutateB: func(c *Config) {
+				c.S3 = &S3Config{Endpoint: "s3.example.com", AccessKey: NewSecret("key-a")}
+			},
+			wantEq: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			a := base()
+			b := base()
+			if tc.mutateA != nil {
+				tc.mutateA(a)
+			}
+			if tc.mutateB != nil {
+				tc.mutateB(b)
+			}
+
+			ok, reason := a.Equal(b)
+			if ok != tc.wantEq {
+				t.Fatalf("Equal() = %v (reason %q), want %v", ok, reason, tc.wantEq)
+			}
+			if !ok && tc.wantTag != "" && reason[:len(tc.wantTag)] != tc.wantTag {
+				t.Fatalf("Equal() reason = %q, want prefix %q", reason, tc.wantTag)
+			}
+		})
+	}
+}
+
+// TestConfigEqualDefaultFilledVsExplicit checks that a relabel_configs entry
+// relying on DefaultRelabelConfig's defaults is Equal to one that spells the
+// same values out explicitly, since Load fills in the defaults for both
+// before Equal ever compares them.
+func TestConfigEqualDefaultFilledVsExplicit(t *testing.T) {
+	const tmpl = `
+route:
+  receiver: default
+receivers:
+- name: default
+relabel_configs:
+- target_label: team
+  replacement: eng
+%s
+`
+	implicit, err := Load(fmt.Sprintf(tmpl, ""))
+	if err != nil {
+		t.Fatalf("Load (implicit defaults): %v", err)
+	}
+	explicit, err := Load(fmt.Sprintf(tmpl, `  separator: ";"
+  regex: "(.*)"
+  action: replace`))
+	if err != nil {
+		t.Fatalf("Load (explicit defaults): %v", err)
+	}
+
+	if ok, reason := implicit.Equal(explicit); !ok {
+		t.Fatalf("Equal() = false (reason %q), want true for default-filled vs. explicit relabel_configs", reason)
+	}
+}
@@ -0,0 +1,90 @@
+// Copyright 2015 Prometheus Team
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"strings"
+	"sync"
+	"testing"
+)
+
+// TestMarshalUnsafeDoesNotLeakIntoConcurrentString runs MarshalUnsafe and
+// String concurrently on independent Configs many times over; under -race
+// this would previously flag the unsynchronized read/write of the
+// package-level MarshalSecretValue, and (even without -race) String could
+// observe MarshalSecretValue == true mid-flight and emit a real secret
+// value instead of the redacted token.
+func TestMarshalUnsafeDoesNotLeakIntoConcurrentString(t *testing.T) {
+	secretCfg := Config{
+		Route:     &Route{Receiver: "default"},
+		Templates: []string{},
+		S3: &S3Config{
+			Endpoint:  "s3.example.com",
+			Bucket:    "bucket",
+			Region:    "us-east-1",
+			AccessKey: NewSecret("top-secret-access-key"),
+			SecretKey: NewSecret("top-secret-key"),
+		},
+	}
+	redactedCfg := secretCfg
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			if _, err := secretCfg.MarshalUnsafe(); err != nil {
+				t.Errorf("MarshalUnsafe: %v", err)
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			s := redactedCfg.String()
+			if strings.Contains(s, "top-secret") {
+				t.Errorf("String() leaked a real secret value during a concurrent MarshalUnsafe: %s", s)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// TestMarshalUnsafeMaterializesSecrets checks MarshalUnsafe's own contract:
+// it must still reveal real secret values in its own output.
+func TestMarshalUnsafeMaterializesSecrets(t *testing.T) {
+	cfg := Config{
+		Route:     &Route{Receiver: "default"},
+		Templates: []string{},
+		S3: &S3Config{
+			Endpoint:  "s3.example.com",
+			Bucket:    "bucket",
+			Region:    "us-east-1",
+			AccessKey: NewSecret("my-access-key"),
+			SecretKey: NewSecret("my-secret-key"),
+		},
+	}
+
+	out, err := cfg.MarshalUnsafe()
+	if err != nil {
+		t.Fatalf("MarshalUnsafe: %v", err)
+	}
+	if !strings.Contains(out, "my-access-key") || !strings.Contains(out, "my-secret-key") {
+		t.Fatalf("MarshalUnsafe output did not contain real secret values: %s", out)
+	}
+
+	// The original Config must be untouched: marshaling it normally still
+	// redacts, proving MarshalUnsafe copied rather than mutated cfg.
+	if redacted := cfg.String(); strings.Contains(redacted, "my-access-key") {
+		t.Fatalf("MarshalUnsafe mutated the receiver Config: %s", redacted)
+	}
+}
@@ -0,0 +1,109 @@
+// Copyright 2015 Prometheus Team
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import "testing"
+
+// TestRouteMatchGlob checks that match_glob is actually reachable from YAML:
+// it is accepted on a non-root route and its pattern is kept as written. The
+// tests below cover its rejection on the root route (same as match and
+// match_re) and with a malformed pattern.
+func TestRouteMatchGlob(t *testing.T) {
+	const in = `route:
+  receiver: default
+  routes:
+  - receiver: default
+    match_glob:
+      instance: web-*
+receivers:
+- name: default
+`
+	cfg, err := Load(in)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if got := cfg.Route.Routes[0].MatchGlob["instance"]; got != "web-*" {
+		t.Fatalf("Route.Routes[0].MatchGlob[\"instance\"] = %q, want %q", got, "web-*")
+	}
+}
+
+func TestRouteMatchGlobRejectedOnRootRoute(t *testing.T) {
+	const in = `route:
+  receiver: default
+  match_glob:
+    instance: web-*
+receivers:
+- name: default
+`
+	if _, err := Load(in); err == nil {
+		t.Fatalf("Load() = nil error, want error for match_glob on the root route")
+	}
+}
+
+func TestRouteMatchGlobRejectsMalformedPattern(t *testing.T) {
+	const in = `route:
+  receiver: default
+  routes:
+  - receiver: default
+    match_glob:
+      instance: web-[z-a]
+receivers:
+- name: default
+`
+	if _, err := Load(in); err == nil {
+		t.Fatalf("Load() = nil error, want error for a malformed match_glob pattern")
+	}
+}
+
+// TestInhibitRuleMatchGlob checks source_match_glob/target_match_glob are
+// parsed and validated the same way as their match_re siblings.
+func TestInhibitRuleMatchGlob(t *testing.T) {
+	const in = `route:
+  receiver: default
+receivers:
+- name: default
+inhibit_rules:
+- source_match_glob:
+    severity: critical-*
+  target_match_glob:
+    severity: warning-*
+  equal: [alertname]
+`
+	cfg, err := Load(in)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	rule := cfg.InhibitRules[0]
+	if got := rule.SourceMatchGlob["severity"]; got != "critical-*" {
+		t.Fatalf("SourceMatchGlob[\"severity\"] = %q, want %q", got, "critical-*")
+	}
+	if got := rule.TargetMatchGlob["severity"]; got != "warning-*" {
+		t.Fatalf("TargetMatchGlob[\"severity\"] = %q, want %q", got, "warning-*")
+	}
+}
+
+func TestInhibitRuleMatchGlobRejectsMalformedPattern(t *testing.T) {
+	const in = `route:
+  receiver: default
+receivers:
+- name: default
+inhibit_rules:
+- source_match_glob:
+    severity: "[z-a]"
+  equal: [alertname]
+`
+	if _, err := Load(in); err == nil {
+		t.Fatalf("Load() = nil error, want error for a malformed source_match_glob pattern")
+	}
+}
@@ -0,0 +1,251 @@
+// Copyright 2015 Prometheus Team
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"crypto/md5"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/prometheus/common/model"
+)
+
+// RelabelAction is the action to be performed on relabeling.
+type RelabelAction string
+
+// Valid actions for relabeling. Modeled on Prometheus' relabel.Config so
+// that scrape and alert relabeling stay familiar to the same operators.
+const (
+	RelabelReplace   RelabelAction = "replace"
+	RelabelKeep      RelabelAction = "keep"
+	RelabelDrop      RelabelAction = "drop"
+	RelabelHashMod   RelabelAction = "hashmod"
+	RelabelLabelMap  RelabelAction = "labelmap"
+	RelabelLabelDrop RelabelAction = "labeldrop"
+	RelabelLabelKeep RelabelAction = "labelkeep"
+)
+
+// UnmarshalYAML implements the yaml.Unmarshaler interface for RelabelAction.
+func (a *RelabelAction) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var s string
+	if err := unmarshal(&s); err != nil {
+		return err
+	}
+	act := RelabelAction(strings.ToLower(s))
+	switch act {
+	case RelabelReplace, RelabelKeep, RelabelDrop, RelabelHashMod, RelabelLabelMap, RelabelLabelDrop, RelabelLabelKeep:
+		*a = act
+		return nil
+	}
+	return fmt.Errorf("unknown relabel action %q", s)
+}
+
+// relabelTargetRE matches a target_label that may contain capture group
+// references such as "${1}" or "$1", reused verbatim from Prometheus'
+// relabel package so existing relabel_configs keep working unmodified.
+var relabelTargetRE = regexp.MustCompile(`^(?:(?:[a-zA-Z_]|\$(?:\{\w+\}|\w+))+\w*)+$`)
+
+// RelabelConfig allows dynamic rewriting of an alert's label set before it
+// is routed. It is the alerting-side counterpart of Prometheus' scrape-time
+// relabel_configs and can be attached globally or per Route.
+// -------------------------------------------------------------------------
+// RelabelConfig 描述对告警label集合的一次重写规则，是Prometheus采集侧
+// relabel_configs在告警侧的对应物，可以挂在全局配置或单个Route上。常见用途
+// 有丢弃噪声告警（drop/keep）、统一不同Prometheus实例上不一致的label命名
+// （replace/labelmap），或者从已有label拼出新的路由label，例如team。
+type RelabelConfig struct {
+	// SourceLabels 被读取并按Separator拼接成待匹配字符串的label名列表。
+	SourceLabels model.LabelNames `yaml:"source_labels,flow,omitempty" json:"source_labels,omitempty"`
+	// Separator 拼接SourceLabels取值时使用的分隔符，默认";"。
+	Separator string `yaml:"separator,omitempty" json:"separator,omitempty"`
+	// Regex 用于匹配/捕获拼接后的字符串，默认"(.*)"。
+	Regex Regexp `yaml:"regex,omitempty" json:"regex,omitempty"`
+	// Modulus 仅hashmod动作使用，取哈希值对Modulus取模。
+	Modulus uint64 `yaml:"modulus,omitempty" json:"modulus,omitempty"`
+	// TargetLabel 写入结果的label名，replace/hashmod动作必填。
+	TargetLabel string `yaml:"target_label,omitempty" json:"target_label,omitempty"`
+	// Replacement 写入TargetLabel的值模板，支持$1等捕获组引用，默认"$1"。
+	Replacement string `yaml:"replacement,omitempty" json:"replacement,omitempty"`
+	// Action 执行的动作，见RelabelAction，默认replace。
+	Action RelabelAction `yaml:"action,omitempty" json:"action,omitempty"`
+}
+
+// DefaultRelabelConfig returns a RelabelConfig with Prometheus' standard
+// defaults: a no-op "replace" of the first regex capture group.
+func DefaultRelabelConfig() RelabelConfig {
+	return RelabelConfig{
+		Action:      RelabelReplace,
+		Separator:   ";",
+		Regex:       mustNewRegexp("(.*)"),
+		Replacement: "$1",
+	}
+}
+
+func mustNewRegexp(s string) Regexp {
+	re, err := regexp.Compile("^(?:" + s + ")$")
+	if err != nil {
+		panic(err)
+	}
+	return Regexp{Regexp: re, original: s}
+}
+
+// MarshalYAML implements the yaml.Marshaler interface for RelabelConfig. It
+// clears any field that still holds its DefaultRelabelConfig value before
+// marshaling, so a config round-tripped through Load and yaml.Marshal omits
+// `separator: ;`, `regex: (.*)`, `replacement: $1` and `action: replace`
+// noise for relabel_configs entries that never set them explicitly.
+func (c RelabelConfig) MarshalYAML() (interface{}, error) {
+	type plain RelabelConfig
+	out := plain(c)
+	def := DefaultRelabelConfig()
+	if out.Separator == def.Separator {
+		out.Separator = ""
+	}
+	if out.Regex.original == def.Regex.original {
+		out.Regex = Regexp{}
+	}
+	if out.Replacement == def.Replacement {
+		out.Replacement = ""
+	}
+	if out.Action == def.Action {
+		out.Action = ""
+	}
+	return out, nil
+}
+
+// UnmarshalYAML implements the yaml.Unmarshaler interface for RelabelConfig.
+func (c *RelabelConfig) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	*c = DefaultRelabelConfig()
+	type plain RelabelConfig
+	if err := unmarshal((*plain)(c)); err != nil {
+		return err
+	}
+
+	if c.Regex.Regexp == nil {
+		c.Regex = DefaultRelabelConfig().Regex
+	}
+
+	switch c.Action {
+	case RelabelReplace, RelabelHashMod:
+		if c.TargetLabel == "" {
+			return fmt.Errorf("relabel configuration for %s action needs target_label value", c.Action)
+		}
+		if !relabelTargetRE.MatchString(c.TargetLabel) {
+			return fmt.Errorf("%q is invalid 'target_label' for %s action", c.TargetLabel, c.Action)
+		}
+	}
+
+	switch c.Action {
+	case RelabelHashMod:
+		if c.Modulus == 0 {
+			return fmt.Errorf("relabel configuration for hashmod action requires non-zero modulus")
+		}
+	case RelabelLabelMap:
+		if !model.LabelNameRE.MatchString(c.Replacement) {
+			return fmt.Errorf("%q is invalid 'replacement' for %s action", c.Replacement, c.Action)
+		}
+	case RelabelReplace, RelabelKeep, RelabelDrop, RelabelLabelDrop, RelabelLabelKeep:
+		// No additional validation.
+	default:
+		return fmt.Errorf("unknown relabel action %q", c.Action)
+	}
+	return nil
+}
+
+// Process runs labels through cfgs in order and returns the resulting label
+// set. It returns nil if a "keep" or "drop" rule eliminates the label set
+// entirely, mirroring Prometheus' relabel.Process.
+func Process(labels model.LabelSet, cfgs ...*RelabelConfig) model.LabelSet {
+	out := labels.Clone()
+	for _, cfg := range cfgs {
+		out = relabel(out, cfg)
+		if out == nil {
+			return nil
+		}
+	}
+	return out
+}
+
+func relabel(labels model.LabelSet, cfg *RelabelConfig) model.LabelSet {
+	values := make([]string, 0, len(cfg.SourceLabels))
+	for _, ln := range cfg.SourceLabels {
+		values = append(values, string(labels[ln]))
+	}
+	val := strings.Join(values, cfg.Separator)
+
+	switch cfg.Action {
+	case RelabelDrop:
+		if cfg.Regex.MatchString(val) {
+			return nil
+		}
+	case RelabelKeep:
+		if !cfg.Regex.MatchString(val) {
+			return nil
+		}
+	case RelabelReplace:
+		indexes := cfg.Regex.FindStringSubmatchIndex(val)
+		if indexes == nil {
+			break
+		}
+		target := model.LabelName(cfg.Regex.ExpandString(nil, cfg.TargetLabel, val, indexes))
+		if !target.IsValid() {
+			delete(labels, model.LabelName(cfg.TargetLabel))
+			break
+		}
+		res := cfg.Regex.ExpandString(nil, cfg.Replacement, val, indexes)
+		if len(res) == 0 {
+			delete(labels, target)
+			break
+		}
+		labels[target] = model.LabelValue(res)
+	case RelabelHashMod:
+		mod := sum64(md5.Sum([]byte(val))) % cfg.Modulus
+		labels[model.LabelName(cfg.TargetLabel)] = model.LabelValue(fmt.Sprintf("%d", mod))
+	case RelabelLabelMap:
+		out := labels.Clone()
+		for ln, lv := range labels {
+			if cfg.Regex.MatchString(string(ln)) {
+				res := cfg.Regex.ReplaceAllString(string(ln), cfg.Replacement)
+				out[model.LabelName(res)] = lv
+			}
+		}
+		labels = out
+	case RelabelLabelDrop:
+		for ln := range labels {
+			if cfg.Regex.MatchString(string(ln)) {
+				delete(labels, ln)
+			}
+		}
+	case RelabelLabelKeep:
+		for ln := range labels {
+			if !cfg.Regex.MatchString(string(ln)) {
+				delete(labels, ln)
+			}
+		}
+	default:
+		panic(fmt.Errorf("relabel: unknown relabel action type %q", cfg.Action))
+	}
+	return labels
+}
+
+// sum64 folds a 128-bit md5 digest down to 64 bits for use with Modulus.
+func sum64(hash [md5.Size]byte) uint64 {
+	var s uint64
+	for i, b := range hash {
+		shift := uint64((md5.Size - i - 1) * 8)
+		s |= uint64(b) << shift
+	}
+	return s
+}
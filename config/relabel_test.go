@@ -0,0 +1,177 @@
+// Copyright 2015 Prometheus Team
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/prometheus/common/model"
+)
+
+func TestRelabelReplace(t *testing.T) {
+	cfg := &RelabelConfig{
+		SourceLabels: model.LabelNames{"instance"},
+		Regex:        mustNewRegexp(`web-(\d+)`),
+		TargetLabel:  "instance_id",
+		Replacement:  "$1",
+		Action:       RelabelReplace,
+	}
+	out := Process(model.LabelSet{"instance": "web-42"}, cfg)
+	if got, want := out["instance_id"], model.LabelValue("42"); got != want {
+		t.Fatalf("instance_id = %q, want %q", got, want)
+	}
+}
+
+func TestRelabelReplaceNoMatchLeavesLabelsUnchanged(t *testing.T) {
+	cfg := &RelabelConfig{
+		SourceLabels: model.LabelNames{"instance"},
+		Regex:        mustNewRegexp(`db-(\d+)`),
+		TargetLabel:  "instance_id",
+		Replacement:  "$1",
+		Action:       RelabelReplace,
+	}
+	in := model.LabelSet{"instance": "web-42"}
+	out := Process(in.Clone(), cfg)
+	if _, ok := out["instance_id"]; ok {
+		t.Fatalf("instance_id should not be set when the regex doesn't match, got %v", out)
+	}
+}
+
+func TestRelabelKeep(t *testing.T) {
+	cfg := &RelabelConfig{
+		SourceLabels: model.LabelNames{"severity"},
+		Regex:        mustNewRegexp("critical"),
+		Action:       RelabelKeep,
+	}
+	if out := Process(model.LabelSet{"severity": "critical"}, cfg); out == nil {
+		t.Fatalf("expected a matching label set to be kept")
+	}
+	if out := Process(model.LabelSet{"severity": "warning"}, cfg); out != nil {
+		t.Fatalf("expected a non-matching label set to be dropped, got %v", out)
+	}
+}
+
+func TestRelabelDrop(t *testing.T) {
+	cfg := &RelabelConfig{
+		SourceLabels: model.LabelNames{"env"},
+		Regex:        mustNewRegexp("test"),
+		Action:       RelabelDrop,
+	}
+	if out := Process(model.LabelSet{"env": "test"}, cfg); out != nil {
+		t.Fatalf("expected a matching label set to be dropped, got %v", out)
+	}
+	if out := Process(model.LabelSet{"env": "prod"}, cfg); out == nil {
+		t.Fatalf("expected a non-matching label set to be kept")
+	}
+}
+
+func TestRelabelHashMod(t *testing.T) {
+	cfg := &RelabelConfig{
+		SourceLabels: model.LabelNames{"instance"},
+		Separator:    ";",
+		Regex:        mustNewRegexp("(.*)"),
+		TargetLabel:  "shard",
+		Modulus:      4,
+		Action:       RelabelHashMod,
+	}
+	out := Process(model.LabelSet{"instance": "web-1"}, cfg)
+	shard, ok := out["shard"]
+	if !ok {
+		t.Fatalf("expected shard label to be set, got %v", out)
+	}
+
+	// hashmod must be deterministic: the same input always lands in the
+	// same shard, and the shard is always within [0, Modulus).
+	out2 := Process(model.LabelSet{"instance": "web-1"}, cfg)
+	if out2["shard"] != shard {
+		t.Fatalf("hashmod is not deterministic: got %q then %q for the same input", shard, out2["shard"])
+	}
+	n, err := strconv.Atoi(string(shard))
+	if err != nil {
+		t.Fatalf("shard value %q is not an integer: %v", shard, err)
+	}
+	if n < 0 || n >= 4 {
+		t.Fatalf("shard %d out of range [0, 4)", n)
+	}
+}
+
+func TestRelabelLabelMap(t *testing.T) {
+	cfg := &RelabelConfig{
+		Regex:       mustNewRegexp(`__meta_(.*)`),
+		Replacement: "$1",
+		Action:      RelabelLabelMap,
+	}
+	out := Process(model.LabelSet{"__meta_region": "us-east", "severity": "critical"}, cfg)
+	if got, want := out["region"], model.LabelValue("us-east"); got != want {
+		t.Fatalf("region = %q, want %q", got, want)
+	}
+	// labelmap adds the mapped label alongside the original, it does not
+	// rename/remove it.
+	if _, ok := out["__meta_region"]; !ok {
+		t.Fatalf("expected the original __meta_region label to survive labelmap, got %v", out)
+	}
+	if got, want := out["severity"], model.LabelValue("critical"); got != want {
+		t.Fatalf("unrelated label severity = %q, want %q", got, want)
+	}
+}
+
+func TestRelabelLabelDrop(t *testing.T) {
+	cfg := &RelabelConfig{
+		Regex:  mustNewRegexp(`__meta_.*`),
+		Action: RelabelLabelDrop,
+	}
+	out := Process(model.LabelSet{"__meta_region": "us-east", "severity": "critical"}, cfg)
+	if _, ok := out["__meta_region"]; ok {
+		t.Fatalf("expected __meta_region to be dropped, got %v", out)
+	}
+	if got, want := out["severity"], model.LabelValue("critical"); got != want {
+		t.Fatalf("unrelated label severity = %q, want %q", got, want)
+	}
+}
+
+func TestRelabelLabelKeep(t *testing.T) {
+	cfg := &RelabelConfig{
+		Regex:  mustNewRegexp(`severity|env`),
+		Action: RelabelLabelKeep,
+	}
+	out := Process(model.LabelSet{"__meta_region": "us-east", "severity": "critical", "env": "prod"}, cfg)
+	if _, ok := out["__meta_region"]; ok {
+		t.Fatalf("expected __meta_region to be removed by labelkeep, got %v", out)
+	}
+	if got, want := out["severity"], model.LabelValue("critical"); got != want {
+		t.Fatalf("severity = %q, want %q", got, want)
+	}
+	if got, want := out["env"], model.LabelValue("prod"); got != want {
+		t.Fatalf("env = %q, want %q", got, want)
+	}
+}
+
+func TestProcessChainsConfigsAndShortCircuitsOnDrop(t *testing.T) {
+	drop := &RelabelConfig{
+		SourceLabels: model.LabelNames{"env"},
+		Regex:        mustNewRegexp("test"),
+		Action:       RelabelDrop,
+	}
+	replace := &RelabelConfig{
+		SourceLabels: model.LabelNames{"env"},
+		Regex:        mustNewRegexp("(.*)"),
+		TargetLabel:  "never_set",
+		Replacement:  "$1",
+		Action:       RelabelReplace,
+	}
+	if out := Process(model.LabelSet{"env": "test"}, drop, replace); out != nil {
+		t.Fatalf("expected Process to stop at the first dropping config, got %v", out)
+	}
+}
@@ -0,0 +1,174 @@
+// Copyright 2015 Prometheus Team
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const s3Scheme = "s3://"
+
+// templateCacheDir is where remote templates are downloaded to before being
+// substituted into Config.Templates. It is a var rather than a constant so
+// tests (and unusual deployments) can point it elsewhere.
+var templateCacheDir = filepath.Join(os.TempDir(), "alertmanager-templates")
+
+// TemplateFetcher downloads a single remote template reference (e.g. an S3
+// object URI) to a local path, so it can be globbed like any other entry in
+// Config.Templates. Implementations for additional object stores (GCS,
+// Azure Blob, plain HTTP, ...) can be added without touching Load/LoadFile.
+// -------------------------------------------------------------------------
+// TemplateFetcher 负责把一个远程模板引用（如S3对象地址）下载到本地路径，
+// 这样它就能像本地模板一样被Templates字段的glob匹配使用。后续要支持
+// GCS、Azure Blob等存储时，只需新增一个实现，不需要改动Load/LoadFile。
+type TemplateFetcher interface {
+	// Fetch downloads ref and returns the local path it was written to.
+	Fetch(ref string) (localPath string, err error)
+}
+
+// S3Config configures a pluggable object store, used both as a remote
+// template source (see S3Fetcher) and as the destination for rendered
+// chart/screenshot attachments (see S3Uploader in attachment.go).
+// -------------------------------------------------------------------------
+// S3Config 定义对象存储的配置，支持AWS S3和兼容S3协议的服务（如MinIO）。
+// 既用作远程模板源（S3Fetcher），也用作渲染图表/截图附件的上传目的地
+// （attachment.go中的S3Uploader），两者共用同一套endpoint/凭据配置。
+type S3Config struct {
+	Endpoint  string `yaml:"endpoint" json:"endpoint"`
+	Region    string `yaml:"region,omitempty" json:"region,omitempty"`
+	Bucket    string `yaml:"bucket" json:"bucket"`
+	Prefix    string `yaml:"prefix,omitempty" json:"prefix,omitempty"`
+	AccessKey Secret `yaml:"access_key,omitempty" json:"access_key,omitempty"`
+	SecretKey Secret `yaml:"secret_key,omitempty" json:"secret_key,omitempty"`
+	Insecure  bool   `yaml:"insecure,omitempty" json:"insecure,omitempty"`
+}
+
+// UnmarshalYAML implements the yaml.Unmarshaler interface for S3Config.
+func (c *S3Config) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	type plain S3Config
+	if err := unmarshal((*plain)(c)); err != nil {
+		return err
+	}
+	if c.Endpoint == "" {
+		return errors.New("missing endpoint in s3 config")
+	}
+	if c.Bucket == "" {
+		return errors.New("missing bucket in s3 config")
+	}
+	if c.Region == "" {
+		return errors.New("missing region in s3 config")
+	}
+	if c.AccessKey.String() == "" {
+		return errors.New("missing access_key in s3 config")
+	}
+	if c.SecretKey.String() == "" {
+		return errors.New("missing secret_key in s3 config")
+	}
+	return nil
+}
+
+// S3Fetcher is a TemplateFetcher backed by an S3 (or S3-compatible) bucket.
+type S3Fetcher struct {
+	conf   *S3Config
+	client *http.Client
+}
+
+// NewS3Fetcher returns a TemplateFetcher for the given S3Config.
+func NewS3Fetcher(c *S3Config) *S3Fetcher {
+	return &S3Fetcher{conf: c, client: &http.Client{}}
+}
+
+// Fetch downloads ref (an "s3://bucket/key" URI) into templateCacheDir and
+// returns the local path it was written to.
+func (f *S3Fetcher) Fetch(ref string) (string, error) {
+	key := strings.TrimPrefix(ref, s3Scheme)
+	if idx := strings.IndexByte(key, '/'); idx >= 0 {
+		// The bucket segment of an s3:// URI is redundant with
+		// S3Config.Bucket; keep only the object key.
+		key = key[idx+1:]
+	}
+	if f.conf.Prefix != "" {
+		key = strings.TrimPrefix(key, f.conf.Prefix+"/")
+	}
+
+	scheme := "https"
+	if f.conf.Insecure {
+		scheme = "http"
+	}
+	// Built through url.URL rather than string concatenation so that a key
+	// containing "#", "?" or "%" is percent-encoded into the path instead
+	// of being parsed as a URL fragment/query (which http.NewRequest would
+	// otherwise silently truncate the request at).
+	u := &url.URL{Scheme: scheme, Host: f.conf.Endpoint, Path: "/" + f.conf.Bucket + "/" + key}
+
+	req, err := http.NewRequest(http.MethodGet, u.String(), nil)
+	if err != nil {
+		return "", err
+	}
+	if f.conf.AccessKey.String() != "" {
+		signS3Request(req, f.conf, nil)
+	}
+
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", errors.Errorf("fetching %q: unexpected status %d", ref, resp.StatusCode)
+	}
+
+	localPath := filepath.Join(templateCacheDir, f.conf.Bucket, key)
+	if err := os.MkdirAll(filepath.Dir(localPath), 0o755); err != nil {
+		return "", err
+	}
+	out, err := os.Create(localPath)
+	if err != nil {
+		return "", err
+	}
+	defer out.Close()
+	if _, err := io.Copy(out, resp.Body); err != nil {
+		return "", err
+	}
+	return localPath, nil
+}
+
+// RefreshTemplates re-downloads every s3:// entry in c.Templates, rewriting
+// it to the local path it was cached at. It is safe to call repeatedly,
+// e.g. from a SIGHUP reload handler, to pick up template changes without
+// restarting Alertmanager.
+func (c *Config) RefreshTemplates() error {
+	if c.S3 == nil {
+		return nil
+	}
+	fetcher := NewS3Fetcher(c.S3)
+	for i, tf := range c.Templates {
+		if !strings.HasPrefix(tf, s3Scheme) {
+			continue
+		}
+		local, err := fetcher.Fetch(tf)
+		if err != nil {
+			return errors.Wrapf(err, "fetching template %q", tf)
+		}
+		c.Templates[i] = local
+	}
+	return nil
+}
@@ -0,0 +1,54 @@
+// Copyright 2015 Prometheus Team
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// TestS3FetcherEscapesSpecialCharactersInKey checks that a template key
+// containing "#", "?" or a space reaches the server as the literal object
+// key rather than being parsed as a URL fragment/query, which would
+// otherwise silently truncate the request path and fetch the wrong object.
+func TestS3FetcherEscapesSpecialCharactersInKey(t *testing.T) {
+	var gotPath string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		w.Write([]byte("template body"))
+	}))
+	defer srv.Close()
+
+	conf := &S3Config{
+		Endpoint:  strings.TrimPrefix(srv.URL, "http://"),
+		Region:    "us-east-1",
+		Bucket:    "bucket",
+		AccessKey: NewSecret("AKIDEXAMPLE"),
+		SecretKey: NewSecret("wJalrXUtnFEMI/K7MDENG/bPxRfiCYEXAMPLEKEY"),
+		Insecure:  true,
+	}
+	f := NewS3Fetcher(conf)
+
+	const key = "a#b c?d.tmpl"
+	if _, err := f.Fetch(s3Scheme + "bucket/" + key); err != nil {
+		t.Fatalf("Fetch: %v", err)
+	}
+
+	want := "/bucket/" + key
+	if gotPath != want {
+		t.Fatalf("server saw request path %q, want %q (key was truncated/mis-parsed)", gotPath, want)
+	}
+}
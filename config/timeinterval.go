@@ -0,0 +1,346 @@
+// Copyright 2015 Prometheus Team
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// TimeInterval is a named, reusable set of time windows that a Route can
+// reference from mute_time_intervals/active_time_intervals to implement
+// maintenance windows.
+// -------------------------------------------------------------------------
+// TimeInterval 是一组可复用、有名字的时间窗口，Route可以通过
+// mute_time_intervals/active_time_intervals引用它来实现维护窗口（静默期/
+// 生效期）功能。一个TimeInterval下的多个TimeIntervalItem是“或”的关系，
+// 任意一个匹配即认为命中这个TimeInterval。
+type TimeInterval struct {
+	Name          string             `yaml:"name" json:"name"`
+	TimeIntervals []TimeIntervalItem `yaml:"time_intervals" json:"time_intervals"`
+}
+
+// TimeIntervalItem describes a single time window. All fields within an
+// item are "and"ed together; an empty field matches every value for that
+// dimension (e.g. no Months means "every month").
+type TimeIntervalItem struct {
+	Times       []TimeRange       `yaml:"times,omitempty" json:"times,omitempty"`
+	Weekdays    []WeekdayRange    `yaml:"weekdays,omitempty" json:"weekdays,omitempty"`
+	DaysOfMonth []DayOfMonthRange `yaml:"days_of_month,omitempty" json:"days_of_month,omitempty"`
+	Months      []MonthRange      `yaml:"months,omitempty" json:"months,omitempty"`
+	Years       []YearRange       `yaml:"years,omitempty" json:"years,omitempty"`
+	Location    string            `yaml:"location,omitempty" json:"location,omitempty"`
+}
+
+// ContainsTime reports whether t falls inside any TimeIntervalItem of ti.
+func (ti *TimeInterval) ContainsTime(t time.Time) bool {
+	for _, item := range ti.TimeIntervals {
+		if item.containsTime(t) {
+			return true
+		}
+	}
+	return false
+}
+
+func (item TimeIntervalItem) containsTime(t time.Time) bool {
+	loc := time.UTC
+	if item.Location != "" {
+		l, err := time.LoadLocation(item.Location)
+		if err == nil {
+			loc = l
+		}
+	}
+	t = t.In(loc)
+
+	if len(item.Times) > 0 && !anyTimeRangeContains(item.Times, t) {
+		return false
+	}
+	if len(item.Weekdays) > 0 && !anyWeekdayContains(item.Weekdays, t.Weekday()) {
+		return false
+	}
+	if len(item.Months) > 0 && !anyMonthContains(item.Months, int(t.Month())) {
+		return false
+	}
+	if len(item.Years) > 0 && !anyYearContains(item.Years, t.Year()) {
+		return false
+	}
+	if len(item.DaysOfMonth) > 0 && !anyDayOfMonthContains(item.DaysOfMonth, t) {
+		return false
+	}
+	return true
+}
+
+// TimeRange is a half-open [Start, End) window within a single day,
+// expressed in minutes since midnight, parsed from "HH:MM-HH:MM".
+type TimeRange struct {
+	StartMinute int
+	EndMinute   int
+}
+
+// UnmarshalYAML implements the yaml.Unmarshaler interface for TimeRange.
+func (tr *TimeRange) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var s string
+	if err := unmarshal(&s); err != nil {
+		return err
+	}
+	parts := strings.SplitN(s, "-", 2)
+	if len(parts) != 2 {
+		return fmt.Errorf("invalid time range %q, expected HH:MM-HH:MM", s)
+	}
+	start, err := parseHHMM(parts[0])
+	if err != nil {
+		return fmt.Errorf("invalid time range %q: %w", s, err)
+	}
+	end, err := parseHHMM(parts[1])
+	if err != nil {
+		return fmt.Errorf("invalid time range %q: %w", s, err)
+	}
+	if start >= end {
+		return fmt.Errorf("invalid time range %q: start must be before end", s)
+	}
+	tr.StartMinute, tr.EndMinute = start, end
+	return nil
+}
+
+func parseHHMM(s string) (int, error) {
+	var h, m int
+	if _, err := fmt.Sscanf(s, "%d:%d", &h, &m); err != nil {
+		return 0, err
+	}
+	if h < 0 || h > 24 || m < 0 || m >= 60 || (h == 24 && m != 0) {
+		return 0, fmt.Errorf("out of range")
+	}
+	return h*60 + m, nil
+}
+
+func anyTimeRangeContains(trs []TimeRange, t time.Time) bool {
+	minute := t.Hour()*60 + t.Minute()
+	for _, tr := range trs {
+		if minute >= tr.StartMinute && minute < tr.EndMinute {
+			return true
+		}
+	}
+	return false
+}
+
+// WeekdayRange is an inclusive range of weekdays, parsed from a single name
+// ("monday") or a "begin:end" pair ("friday:sunday").
+type WeekdayRange struct {
+	Begin, End time.Weekday
+}
+
+var weekdayNames = map[string]time.Weekday{
+	"sunday": time.Sunday, "monday": time.Monday, "tuesday": time.Tuesday,
+	"wednesday": time.Wednesday, "thursday": time.Thursday, "friday": time.Friday,
+	"saturday": time.Saturday,
+}
+
+// UnmarshalYAML implements the yaml.Unmarshaler interface for WeekdayRange.
+func (wr *WeekdayRange) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var s string
+	if err := unmarshal(&s); err != nil {
+		return err
+	}
+	begin, end, err := parseNamedRange(s, weekdayNames)
+	if err != nil {
+		return fmt.Errorf("invalid weekday range %q: %w", s, err)
+	}
+	wr.Begin, wr.End = time.Weekday(begin), time.Weekday(end)
+	return nil
+}
+
+func anyWeekdayContains(wrs []WeekdayRange, day time.Weekday) bool {
+	for _, wr := range wrs {
+		if intRangeContains(int(wr.Begin), int(wr.End), int(day), 7) {
+			return true
+		}
+	}
+	return false
+}
+
+// MonthRange is an inclusive range of months (1-12), parsed from a name
+// ("january"), a number ("1"), or a "begin:end" pair ("december:february").
+type MonthRange struct {
+	Begin, End int
+}
+
+func monthNumber(s string) (int, bool) {
+	months := []string{"january", "february", "march", "april", "may", "june",
+		"july", "august", "september", "october", "november", "december"}
+	for i, m := range months {
+		if strings.EqualFold(m, s) {
+			return i + 1, true
+		}
+	}
+	return 0, false
+}
+
+// UnmarshalYAML implements the yaml.Unmarshaler interface for MonthRange.
+func (mr *MonthRange) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var s string
+	if err := unmarshal(&s); err != nil {
+		return err
+	}
+	parts := strings.SplitN(s, ":", 2)
+	begin, err := parseMonthPart(parts[0])
+	if err != nil {
+		return fmt.Errorf("invalid month range %q: %w", s, err)
+	}
+	end := begin
+	if len(parts) == 2 {
+		end, err = parseMonthPart(parts[1])
+		if err != nil {
+			return fmt.Errorf("invalid month range %q: %w", s, err)
+		}
+	}
+	mr.Begin, mr.End = begin, end
+	return nil
+}
+
+func parseMonthPart(s string) (int, error) {
+	if n, ok := monthNumber(s); ok {
+		return n, nil
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil || n < 1 || n > 12 {
+		return 0, fmt.Errorf("invalid month %q", s)
+	}
+	return n, nil
+}
+
+func anyMonthContains(mrs []MonthRange, month int) bool {
+	for _, mr := range mrs {
+		if intRangeContains(mr.Begin-1, mr.End-1, month-1, 12) {
+			return true
+		}
+	}
+	return false
+}
+
+// YearRange is an inclusive range of years, parsed from "2030" or
+// "2030:2035".
+type YearRange struct {
+	Begin, End int
+}
+
+// UnmarshalYAML implements the yaml.Unmarshaler interface for YearRange.
+func (yr *YearRange) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var s string
+	if err := unmarshal(&s); err != nil {
+		return err
+	}
+	parts := strings.SplitN(s, ":", 2)
+	begin, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return fmt.Errorf("invalid year range %q", s)
+	}
+	end := begin
+	if len(parts) == 2 {
+		end, err = strconv.Atoi(parts[1])
+		if err != nil {
+			return fmt.Errorf("invalid year range %q", s)
+		}
+	}
+	if begin > end {
+		return fmt.Errorf("invalid year range %q: begin after end", s)
+	}
+	yr.Begin, yr.End = begin, end
+	return nil
+}
+
+func anyYearContains(yrs []YearRange, year int) bool {
+	for _, yr := range yrs {
+		if year >= yr.Begin && year <= yr.End {
+			return true
+		}
+	}
+	return false
+}
+
+// DayOfMonthRange is an inclusive range of days within a month. Negative
+// values count backwards from the last day of the month (-1 is the last
+// day), mirroring cron-style "last N days" maintenance windows.
+type DayOfMonthRange struct {
+	Begin, End int
+}
+
+// UnmarshalYAML implements the yaml.Unmarshaler interface for DayOfMonthRange.
+func (dr *DayOfMonthRange) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var s string
+	if err := unmarshal(&s); err != nil {
+		return err
+	}
+	parts := strings.SplitN(s, ":", 2)
+	begin, err := strconv.Atoi(parts[0])
+	if err != nil || begin == 0 || begin < -31 || begin > 31 {
+		return fmt.Errorf("invalid days_of_month %q", s)
+	}
+	end := begin
+	if len(parts) == 2 {
+		end, err = strconv.Atoi(parts[1])
+		if err != nil || end == 0 || end < -31 || end > 31 {
+			return fmt.Errorf("invalid days_of_month %q", s)
+		}
+	}
+	dr.Begin, dr.End = begin, end
+	return nil
+}
+
+func anyDayOfMonthContains(drs []DayOfMonthRange, t time.Time) bool {
+	lastDay := time.Date(t.Year(), t.Month()+1, 0, 0, 0, 0, 0, t.Location()).Day()
+	day := t.Day()
+	for _, dr := range drs {
+		begin, end := dr.Begin, dr.End
+		if begin < 0 {
+			begin = lastDay + begin + 1
+		}
+		if end < 0 {
+			end = lastDay + end + 1
+		}
+		if begin <= end && day >= begin && day <= end {
+			return true
+		}
+	}
+	return false
+}
+
+// intRangeContains reports whether v falls within [begin, end] modulo m,
+// allowing wraparound ranges like weekdays "friday:monday".
+func intRangeContains(begin, end, v, m int) bool {
+	begin, end, v = ((begin%m)+m)%m, ((end%m)+m)%m, ((v%m)+m)%m
+	if begin <= end {
+		return v >= begin && v <= end
+	}
+	return v >= begin || v <= end
+}
+
+// parseNamedRange parses a single name or "begin:end" pair against names,
+// returning the corresponding 0-based indices into the weekday table.
+func parseNamedRange(s string, names map[string]time.Weekday) (int, int, error) {
+	parts := strings.SplitN(s, ":", 2)
+	begin, ok := names[strings.ToLower(parts[0])]
+	if !ok {
+		return 0, 0, fmt.Errorf("unknown name %q", parts[0])
+	}
+	end := begin
+	if len(parts) == 2 {
+		end, ok = names[strings.ToLower(parts[1])]
+		if !ok {
+			return 0, 0, fmt.Errorf("unknown name %q", parts[1])
+		}
+	}
+	return int(begin), int(end), nil
+}
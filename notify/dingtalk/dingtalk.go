@@ -0,0 +1,175 @@
+// Copyright 2015 Prometheus Team
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package dingtalk implements a Notifier for DingTalk (钉钉) custom robot
+// notifications.
+// -------------------------------------------------------------------------
+// dingtalk 包实现了钉钉自定义机器人渠道的Notifier。当配置了加签密钥时，会
+// 按照钉钉开放平台的要求计算timestamp和sign，作为query参数拼接到webhook
+// 地址后面。
+package dingtalk
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+	commoncfg "github.com/prometheus/common/config"
+
+	"github.com/codecomment/alertmanager-with-comment/config"
+	"github.com/codecomment/alertmanager-with-comment/notify"
+	"github.com/codecomment/alertmanager-with-comment/template"
+	"github.com/codecomment/alertmanager-with-comment/types"
+)
+
+// Notifier implements a Notifier for DingTalk notifications.
+type Notifier struct {
+	conf    *config.DingtalkConfig
+	tmpl    *template.Template
+	logger  log.Logger
+	client  *http.Client
+	retrier *notify.Retrier
+}
+
+// New returns a new DingTalk notifier.
+func New(c *config.DingtalkConfig, t *template.Template, l log.Logger, httpOpts ...commoncfg.HTTPClientOption) (*Notifier, error) {
+	client, err := commoncfg.NewClientFromConfig(*c.HTTPConfig, "dingtalk", httpOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return &Notifier{
+		conf:    c,
+		tmpl:    t,
+		logger:  l,
+		client:  client,
+		retrier: &notify.Retrier{},
+	}, nil
+}
+
+type at struct {
+	AtMobiles []string `json:"atMobiles,omitempty"`
+	IsAtAll   bool     `json:"isAtAll,omitempty"`
+}
+
+type markdownMsg struct {
+	Title string `json:"title"`
+	Text  string `json:"text"`
+}
+
+type textMsg struct {
+	Content string `json:"content"`
+}
+
+type actionCardMsg struct {
+	Title       string `json:"title"`
+	Text        string `json:"text"`
+	SingleTitle string `json:"singleTitle,omitempty"`
+	SingleURL   string `json:"singleURL,omitempty"`
+}
+
+type robotPayload struct {
+	MsgType    string         `json:"msgtype"`
+	Markdown   *markdownMsg   `json:"markdown,omitempty"`
+	Text       *textMsg       `json:"text,omitempty"`
+	ActionCard *actionCardMsg `json:"actionCard,omitempty"`
+	At         at             `json:"at,omitempty"`
+}
+
+// Notify implements the Notifier interface.
+func (n *Notifier) Notify(ctx context.Context, as ...*types.Alert) (bool, error) {
+	data := notify.GetTemplateData(ctx, n.tmpl, as, n.logger)
+	tmpl := notify.TmplText(n.tmpl, data, nil)
+
+	payload := robotPayload{
+		MsgType: n.conf.MessageType,
+		At: at{
+			AtMobiles: n.conf.AtMobiles,
+			IsAtAll:   n.conf.AtAll,
+		},
+	}
+	switch n.conf.MessageType {
+	case "markdown":
+		payload.Markdown = &markdownMsg{
+			Title: tmpl(n.conf.Markdown.Title),
+			Text:  tmpl(n.conf.Markdown.Text),
+		}
+	case "text":
+		payload.Text = &textMsg{Content: tmpl(n.conf.Text)}
+	case "actionCard":
+		payload.ActionCard = &actionCardMsg{
+			Title:       tmpl(n.conf.ActionCard.Title),
+			Text:        tmpl(n.conf.ActionCard.Text),
+			SingleTitle: tmpl(n.conf.ActionCard.SingleText),
+			SingleURL:   tmpl(n.conf.ActionCard.SingleURL),
+		}
+	default:
+		return false, fmt.Errorf("unsupported dingtalk message_type %q", n.conf.MessageType)
+	}
+
+	var buf bytes.Buffer
+	if err := json.NewEncoder(&buf).Encode(payload); err != nil {
+		return false, err
+	}
+
+	webhookURL, err := n.signedURL()
+	if err != nil {
+		return false, err
+	}
+
+	resp, err := notify.PostJSON(ctx, n.client, webhookURL, &buf)
+	if err != nil {
+		return true, notify.RedactURL(err)
+	}
+	defer notify.Drain(resp)
+
+	shouldRetry, err := n.retrier.Check(resp.StatusCode, resp.Body)
+	if err != nil {
+		level.Debug(n.logger).Log("msg", "failed to post to DingTalk", "err", fmt.Sprintf("%v", err))
+	}
+	return shouldRetry, err
+}
+
+// signedURL appends the `timestamp` and `sign` query parameters required by
+// the DingTalk custom robot API when a signing secret is configured.
+func (n *Notifier) signedURL() (string, error) {
+	u, err := url.Parse(n.conf.WebhookURL.String())
+	if err != nil {
+		return "", err
+	}
+	if n.conf.Secret.String() == "" {
+		return u.String(), nil
+	}
+
+	timestamp := time.Now().UnixNano() / int64(time.Millisecond)
+	stringToSign := fmt.Sprintf("%d\n%s", timestamp, n.conf.Secret.String())
+
+	mac := hmac.New(sha256.New, []byte(n.conf.Secret.String()))
+	mac.Write([]byte(stringToSign))
+	sign := base64.StdEncoding.EncodeToString(mac.Sum(nil))
+
+	q := u.Query()
+	q.Set("timestamp", strconv.FormatInt(timestamp, 10))
+	q.Set("sign", sign)
+	u.RawQuery = q.Encode()
+	return u.String(), nil
+}
@@ -0,0 +1,139 @@
+// Copyright 2015 Prometheus Team
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dingtalk
+
+import (
+	"encoding/json"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/codecomment/alertmanager-with-comment/config"
+)
+
+// TestRobotPayloadEnvelopeShape checks that the JSON envelope for each
+// message_type matches what DingTalk's custom robot webhook expects: the
+// top-level msgtype discriminator plus exactly the one matching payload
+// sub-object, with "at" always present.
+func TestRobotPayloadEnvelopeShape(t *testing.T) {
+	tests := []struct {
+		name    string
+		payload robotPayload
+		wantKey string
+	}{
+		{
+			name: "markdown",
+			payload: robotPayload{
+				MsgType:  "markdown",
+				Markdown: &markdownMsg{Title: "t", Text: "body"},
+				At:       at{AtMobiles: []string{"10000000000"}, IsAtAll: false},
+			},
+			wantKey: "markdown",
+		},
+		{
+			name: "text",
+			payload: robotPayload{
+				MsgType: "text",
+				Text:    &textMsg{Content: "body"},
+				At:      at{IsAtAll: true},
+			},
+			wantKey: "text",
+		},
+		{
+			name: "actionCard",
+			payload: robotPayload{
+				MsgType:    "actionCard",
+				ActionCard: &actionCardMsg{Title: "t", Text: "body", SingleTitle: "open", SingleURL: "https://example.com"},
+			},
+			wantKey: "actionCard",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			b, err := json.Marshal(tc.payload)
+			if err != nil {
+				t.Fatalf("Marshal: %v", err)
+			}
+			var raw map[string]json.RawMessage
+			if err := json.Unmarshal(b, &raw); err != nil {
+				t.Fatalf("Unmarshal: %v", err)
+			}
+
+			if string(raw["msgtype"]) != `"`+tc.payload.MsgType+`"` {
+				t.Fatalf("msgtype = %s, want %q", raw["msgtype"], tc.payload.MsgType)
+			}
+			if _, ok := raw[tc.wantKey]; !ok {
+				t.Fatalf("envelope missing %q key: %s", tc.wantKey, b)
+			}
+			if _, ok := raw["at"]; !ok {
+				t.Fatalf("envelope missing %q key: %s", "at", b)
+			}
+			for _, other := range []string{"markdown", "text", "actionCard"} {
+				if other == tc.wantKey {
+					continue
+				}
+				if _, ok := raw[other]; ok {
+					t.Fatalf("envelope unexpectedly has %q key alongside %q: %s", other, tc.wantKey, b)
+				}
+			}
+		})
+	}
+}
+
+// TestSignedURLAppendsTimestampAndSign checks that a configured Secret
+// results in timestamp and sign query parameters being appended, per the
+// DingTalk custom robot signing scheme, and that an unconfigured Secret
+// leaves the URL untouched.
+func TestSignedURLAppendsTimestampAndSign(t *testing.T) {
+	webhook, err := url.Parse("https://oapi.dingtalk.com/robot/send?access_token=abc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	secretURL := config.NewSecretURL(&config.URL{URL: webhook})
+
+	n := &Notifier{conf: &config.DingtalkConfig{
+		WebhookURL: &secretURL,
+		Secret:     config.NewUnsafeSecret("shh"),
+	}}
+
+	signed, err := n.signedURL()
+	if err != nil {
+		t.Fatalf("signedURL: %v", err)
+	}
+	u, err := url.Parse(signed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	q := u.Query()
+	if q.Get("timestamp") == "" {
+		t.Fatalf("signedURL() = %q, missing timestamp", signed)
+	}
+	if q.Get("sign") == "" {
+		t.Fatalf("signedURL() = %q, missing sign", signed)
+	}
+	if q.Get("access_token") != "abc" {
+		t.Fatalf("signedURL() dropped original query params: %q", signed)
+	}
+
+	unsignedConf := &config.DingtalkConfig{WebhookURL: &secretURL}
+	nNoSecret := &Notifier{conf: unsignedConf}
+	plain, err := nNoSecret.signedURL()
+	if err != nil {
+		t.Fatalf("signedURL: %v", err)
+	}
+	if strings.Contains(plain, "sign=") {
+		t.Fatalf("signedURL() = %q, want no sign param without a configured secret", plain)
+	}
+}
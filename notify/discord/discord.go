@@ -0,0 +1,124 @@
+// Copyright 2015 Prometheus Team
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package discord implements a Notifier for Discord notifications.
+// ----------------------------------------------------------------
+// discord 包实现了向Discord频道投递告警通知的Notifier，通过webhook url
+// POST一个Discord兼容的JSON payload（content + embeds），并根据告警状态
+// （firing/resolved）设置embed的颜色。
+package discord
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+	commoncfg "github.com/prometheus/common/config"
+
+	"github.com/codecomment/alertmanager-with-comment/config"
+	"github.com/codecomment/alertmanager-with-comment/notify"
+	"github.com/codecomment/alertmanager-with-comment/template"
+	"github.com/codecomment/alertmanager-with-comment/types"
+)
+
+const (
+	colorFiring   = 0xD32F2F
+	colorResolved = 0x388E3C
+)
+
+// Notifier implements a Notifier for Discord notifications.
+type Notifier struct {
+	conf    *config.DiscordConfig
+	tmpl    *template.Template
+	logger  log.Logger
+	client  *http.Client
+	retrier *notify.Retrier
+}
+
+// New returns a new Discord notifier.
+func New(c *config.DiscordConfig, t *template.Template, l log.Logger, httpOpts ...commoncfg.HTTPClientOption) (*Notifier, error) {
+	client, err := commoncfg.NewClientFromConfig(*c.HTTPConfig, "discord", httpOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return &Notifier{
+		conf:    c,
+		tmpl:    t,
+		logger:  l,
+		client:  client,
+		retrier: &notify.Retrier{},
+	}, nil
+}
+
+// webhookPayload mirrors the subset of the Discord webhook execute API
+// (https://discord.com/developers/docs/resources/webhook#execute-webhook)
+// that this notifier needs.
+type webhookPayload struct {
+	Content   string  `json:"content,omitempty"`
+	Username  string  `json:"username,omitempty"`
+	AvatarURL string  `json:"avatar_url,omitempty"`
+	Embeds    []embed `json:"embeds,omitempty"`
+}
+
+type embed struct {
+	Title       string `json:"title,omitempty"`
+	Description string `json:"description,omitempty"`
+	Color       int    `json:"color,omitempty"`
+}
+
+// Notify implements the Notifier interface.
+func (n *Notifier) Notify(ctx context.Context, as ...*types.Alert) (bool, error) {
+	data := notify.GetTemplateData(ctx, n.tmpl, as, n.logger)
+	tmpl := notify.TmplText(n.tmpl, data, nil)
+
+	title := tmpl(n.conf.Title)
+	message := tmpl(n.conf.Message)
+
+	color := colorFiring
+	if data.Status == "resolved" {
+		color = colorResolved
+	}
+
+	payload := webhookPayload{
+		Content:   message,
+		Username:  n.conf.Username,
+		AvatarURL: n.conf.AvatarURL,
+		Embeds: []embed{{
+			Title:       title,
+			Description: message,
+			Color:       color,
+		}},
+	}
+
+	var buf bytes.Buffer
+	if err := json.NewEncoder(&buf).Encode(payload); err != nil {
+		return false, err
+	}
+
+	url := n.conf.WebhookURL.String()
+	resp, err := notify.PostJSON(ctx, n.client, url, &buf)
+	if err != nil {
+		return true, notify.RedactURL(err)
+	}
+	defer notify.Drain(resp)
+
+	shouldRetry, err := n.retrier.Check(resp.StatusCode, resp.Body)
+	if err != nil {
+		level.Debug(n.logger).Log("msg", "failed to post to Discord", "err", fmt.Sprintf("%v", err))
+	}
+	return shouldRetry, err
+}
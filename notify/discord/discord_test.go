@@ -0,0 +1,102 @@
+// Copyright 2015 Prometheus Team
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package discord
+
+import (
+	"encoding/json"
+	"testing"
+
+	"gopkg.in/yaml.v2"
+
+	"github.com/codecomment/alertmanager-with-comment/config"
+)
+
+// TestWebhookPayloadEnvelopeShape checks that the JSON envelope matches what
+// Discord's webhook execute API expects: content and a single embed with
+// title/description/color set, and that color switches with alert status.
+func TestWebhookPayloadEnvelopeShape(t *testing.T) {
+	tests := []struct {
+		name      string
+		status    string
+		wantColor int
+	}{
+		{name: "firing", status: "firing", wantColor: colorFiring},
+		{name: "resolved", status: "resolved", wantColor: colorResolved},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			color := colorFiring
+			if tc.status == "resolved" {
+				color = colorResolved
+			}
+			payload := webhookPayload{
+				Content:  "body",
+				Username: "Alertmanager",
+				Embeds: []embed{{
+					Title:       "title",
+					Description: "body",
+					Color:       color,
+				}},
+			}
+
+			b, err := json.Marshal(payload)
+			if err != nil {
+				t.Fatalf("Marshal: %v", err)
+			}
+			var raw map[string]json.RawMessage
+			if err := json.Unmarshal(b, &raw); err != nil {
+				t.Fatalf("Unmarshal: %v", err)
+			}
+			if _, ok := raw["content"]; !ok {
+				t.Fatalf("envelope missing %q key: %s", "content", b)
+			}
+			var embeds []embed
+			if err := json.Unmarshal(raw["embeds"], &embeds); err != nil {
+				t.Fatalf("Unmarshal embeds: %v", err)
+			}
+			if len(embeds) != 1 {
+				t.Fatalf("embeds = %v, want exactly one", embeds)
+			}
+			if embeds[0].Color != tc.wantColor {
+				t.Fatalf("embed color = %#x, want %#x", embeds[0].Color, tc.wantColor)
+			}
+		})
+	}
+}
+
+// TestDiscordConfigUnmarshalRequiresWebhookURL checks that a discord_config
+// without webhook_url is rejected.
+func TestDiscordConfigUnmarshalRequiresWebhookURL(t *testing.T) {
+	var c config.DiscordConfig
+	err := yaml.Unmarshal([]byte(`{}`), &c)
+	if err == nil {
+		t.Fatalf("expected an error for a discord_config missing webhook_url")
+	}
+}
+
+// TestDiscordConfigUnmarshalFillsDefaults checks that title/message default
+// to the built-in templates when omitted.
+func TestDiscordConfigUnmarshalFillsDefaults(t *testing.T) {
+	var c config.DiscordConfig
+	if err := yaml.Unmarshal([]byte(`webhook_url: https://discord.com/api/webhooks/1/abc`), &c); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	if c.Title != config.DefaultDiscordTitle {
+		t.Fatalf("Title = %q, want default %q", c.Title, config.DefaultDiscordTitle)
+	}
+	if c.Message != config.DefaultDiscordMessage {
+		t.Fatalf("Message = %q, want default %q", c.Message, config.DefaultDiscordMessage)
+	}
+}
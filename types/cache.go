@@ -0,0 +1,168 @@
+// Copyright 2015 Prometheus Team
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package types
+
+import (
+	"container/list"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/common/model"
+)
+
+// Matchable is satisfied by anything that can be evaluated against a label
+// set, the same contract *Matcher already fulfills. CachedMatcher and
+// Matchers.Cached return a Matchable instead of a *Matcher/Matchers so that
+// callers on the hot dispatcher/silence/inhibition paths can swap in a cache
+// without changing their own interfaces.
+// -------------------------------------------------------------------------
+// Matchable 是*Matcher已经满足的最小契约：针对一个label集合给出是否匹配。
+// CachedMatcher和Matchers.Cached都返回Matchable而不是*Matcher/Matchers，
+// 这样dispatcher、silence、inhibition等高频调用路径可以直接替换成带缓存的
+// 版本，而不需要改动自己的接口类型。
+type Matchable interface {
+	Match(lset model.LabelSet) bool
+}
+
+var (
+	cacheHits = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "alertmanager_matcher_cache_hits_total",
+		Help: "Total number of cache hits for a matcher result cache.",
+	}, []string{"matcher"})
+	cacheMisses = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "alertmanager_matcher_cache_misses_total",
+		Help: "Total number of cache misses for a matcher result cache.",
+	}, []string{"matcher"})
+	cacheEvictions = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "alertmanager_matcher_cache_evictions_total",
+		Help: "Total number of entries evicted from a matcher result cache.",
+	}, []string{"matcher"})
+)
+
+// CachedMatcher wraps a *Matcher with a fixed-size LRU cache keyed on a
+// fingerprint of the single label the matcher reads, so repeated evaluations
+// of the same label value skip straight to the cached boolean result instead
+// of re-running the underlying equality/regex/glob check. This pays off most
+// on the regex path, where dispatcher routing, silence lookups and
+// inhibition checks otherwise re-run the same regexp many times per second
+// against the same handful of recurring label values.
+// -------------------------------------------------------------------------
+// CachedMatcher 把一个*Matcher包装成带固定大小LRU缓存的版本，缓存key是
+// 对该matcher读取的那个label取值的指纹，重复对同一个label值求值时可以
+// 直接命中缓存，不必重新跑一遍等值/正则/glob判断。在正则匹配上收益最大，
+// dispatcher路由、静默查找、抑制规则检查都会对同一批反复出现的label值
+// 反复求值。
+type CachedMatcher struct {
+	m    *Matcher
+	size int
+
+	mtx        sync.Mutex
+	ll         *list.List
+	items      map[model.Fingerprint]*list.Element
+	generation uint64 // 最近一次观察到的m.generation，用于发现Init带来的失效
+}
+
+type cacheEntry struct {
+	key    model.Fingerprint
+	result bool
+}
+
+// NewCachedMatcher returns a Matchable that caches up to size distinct
+// results for m. A size of 0 disables caching and simply evaluates m every
+// time.
+func NewCachedMatcher(m *Matcher, size int) *CachedMatcher {
+	return &CachedMatcher{
+		m:          m,
+		size:       size,
+		ll:         list.New(),
+		items:      make(map[model.Fingerprint]*list.Element, size),
+		generation: m.generation,
+	}
+}
+
+// Match implements Matchable, evaluating the wrapped Matcher and caching the
+// result keyed on the value of the label it reads.
+func (c *CachedMatcher) Match(lset model.LabelSet) bool {
+	if c.size <= 0 {
+		return c.m.Match(lset)
+	}
+
+	c.mtx.Lock()
+	if c.m.generation != c.generation {
+		c.generation = c.m.generation
+		c.ll.Init()
+		c.items = make(map[model.Fingerprint]*list.Element, c.size)
+	}
+	c.mtx.Unlock()
+
+	key := model.LabelSet{model.LabelName(c.m.Name): lset[model.LabelName(c.m.Name)]}.Fingerprint()
+	name := c.m.Name
+
+	c.mtx.Lock()
+	if el, ok := c.items[key]; ok {
+		c.ll.MoveToFront(el)
+		result := el.Value.(*cacheEntry).result
+		c.mtx.Unlock()
+		cacheHits.WithLabelValues(name).Inc()
+		return result
+	}
+	c.mtx.Unlock()
+
+	cacheMisses.WithLabelValues(name).Inc()
+	result := c.m.Match(lset)
+
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	if el, ok := c.items[key]; ok {
+		c.ll.MoveToFront(el)
+		el.Value.(*cacheEntry).result = result
+		return result
+	}
+	el := c.ll.PushFront(&cacheEntry{key: key, result: result})
+	c.items[key] = el
+	if c.ll.Len() > c.size {
+		oldest := c.ll.Back()
+		if oldest != nil {
+			c.ll.Remove(oldest)
+			delete(c.items, oldest.Value.(*cacheEntry).key)
+			cacheEvictions.WithLabelValues(name).Inc()
+		}
+	}
+	return result
+}
+
+// cachedMatchers composes one CachedMatcher per leaf and short-circuits on
+// the first false, mirroring Matchers.Match.
+type cachedMatchers []*CachedMatcher
+
+// Match implements Matchable.
+func (cs cachedMatchers) Match(lset model.LabelSet) bool {
+	for _, c := range cs {
+		if !c.Match(lset) {
+			return false
+		}
+	}
+	return true
+}
+
+// Cached returns ms as a Matchable backed by one size-entry LRU cache per
+// matcher, evaluated in order with a short-circuit on the first non-match.
+func (ms Matchers) Cached(size int) Matchable {
+	cached := make(cachedMatchers, len(ms))
+	for i, m := range ms {
+		cached[i] = NewCachedMatcher(m, size)
+	}
+	return cached
+}
@@ -0,0 +1,181 @@
+// Copyright 2015 Prometheus Team
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package types
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/prometheus/common/model"
+)
+
+func newTestCachedMatcher(t *testing.T, name, value string, size int) *CachedMatcher {
+	t.Helper()
+	m := NewMatcher(model.LabelName(name), value)
+	if err := m.Init(); err != nil {
+		t.Fatalf("Init: %v", err)
+	}
+	return NewCachedMatcher(m, size)
+}
+
+// TestCachedMatcherHitsAndMisses checks that repeated lookups for the same
+// label value hit the cache, while distinct values miss, and that the
+// hit/miss counters reflect exactly that.
+func TestCachedMatcherHitsAndMisses(t *testing.T) {
+	c := newTestCachedMatcher(t, "env", "prod", 10)
+	name := c.m.Name
+
+	hitsBefore := testutil.ToFloat64(cacheHits.WithLabelValues(name))
+	missesBefore := testutil.ToFloat64(cacheMisses.WithLabelValues(name))
+
+	if !c.Match(model.LabelSet{"env": "prod"}) {
+		t.Fatalf("expected match on first lookup")
+	}
+	if got := testutil.ToFloat64(cacheMisses.WithLabelValues(name)); got != missesBefore+1 {
+		t.Fatalf("misses after first lookup = %v, want %v", got, missesBefore+1)
+	}
+
+	if !c.Match(model.LabelSet{"env": "prod"}) {
+		t.Fatalf("expected match on cached lookup")
+	}
+	if got := testutil.ToFloat64(cacheHits.WithLabelValues(name)); got != hitsBefore+1 {
+		t.Fatalf("hits after second (cached) lookup = %v, want %v", got, hitsBefore+1)
+	}
+
+	if c.Match(model.LabelSet{"env": "staging"}) {
+		t.Fatalf("expected non-match for a different label value")
+	}
+	if got := testutil.ToFloat64(cacheMisses.WithLabelValues(name)); got != missesBefore+2 {
+		t.Fatalf("misses after distinct-value lookup = %v, want %v", got, missesBefore+2)
+	}
+}
+
+// TestCachedMatcherEvictsLeastRecentlyUsed checks that once the cache is
+// full, the least-recently-used entry (not simply the oldest-inserted one)
+// is evicted, and that eviction is counted.
+func TestCachedMatcherEvictsLeastRecentlyUsed(t *testing.T) {
+	c := newTestCachedMatcher(t, "env", "prod", 2)
+	name := c.m.Name
+	evictionsBefore := testutil.ToFloat64(cacheEvictions.WithLabelValues(name))
+
+	c.Match(model.LabelSet{"env": "a"}) // cache: [a]
+	c.Match(model.LabelSet{"env": "b"}) // cache: [b, a]
+
+	// Touch "a" so "b" becomes the least-recently-used entry.
+	c.Match(model.LabelSet{"env": "a"}) // cache: [a, b], both hits from here
+
+	hitsBefore := testutil.ToFloat64(cacheHits.WithLabelValues(name))
+	c.Match(model.LabelSet{"env": "c"}) // evicts "b", cache: [c, a]
+	if got := testutil.ToFloat64(cacheEvictions.WithLabelValues(name)); got != evictionsBefore+1 {
+		t.Fatalf("evictions = %v, want %v", got, evictionsBefore+1)
+	}
+
+	// "a" should still be cached (hit, no new miss).
+	missesBefore := testutil.ToFloat64(cacheMisses.WithLabelValues(name))
+	c.Match(model.LabelSet{"env": "a"})
+	if got := testutil.ToFloat64(cacheHits.WithLabelValues(name)); got != hitsBefore+1 {
+		t.Fatalf("hits after re-touching \"a\" = %v, want %v", got, hitsBefore+1)
+	}
+	if got := testutil.ToFloat64(cacheMisses.WithLabelValues(name)); got != missesBefore {
+		t.Fatalf("expected no new miss for still-cached \"a\", misses = %v, want %v", got, missesBefore)
+	}
+
+	// "b" was evicted, so it should miss again.
+	missesBefore = testutil.ToFloat64(cacheMisses.WithLabelValues(name))
+	c.Match(model.LabelSet{"env": "b"})
+	if got := testutil.ToFloat64(cacheMisses.WithLabelValues(name)); got != missesBefore+1 {
+		t.Fatalf("expected a miss for evicted \"b\", misses = %v, want %v", got, missesBefore+1)
+	}
+}
+
+// TestCachedMatcherInitInvalidatesCache checks that calling Init on the
+// wrapped Matcher (e.g. after its Value is rewritten by a config reload)
+// invalidates every entry the cache already holds.
+func TestCachedMatcherInitInvalidatesCache(t *testing.T) {
+	c := newTestCachedMatcher(t, "env", "prod", 10)
+	name := c.m.Name
+
+	if !c.Match(model.LabelSet{"env": "prod"}) {
+		t.Fatalf("expected match before Init")
+	}
+	hitsBefore := testutil.ToFloat64(cacheHits.WithLabelValues(name))
+	if !c.Match(model.LabelSet{"env": "prod"}) {
+		t.Fatalf("expected cached match before Init")
+	}
+	if got := testutil.ToFloat64(cacheHits.WithLabelValues(name)); got != hitsBefore+1 {
+		t.Fatalf("expected a cache hit before Init, hits = %v, want %v", got, hitsBefore+1)
+	}
+
+	c.m.Value = "staging"
+	if err := c.m.Init(); err != nil {
+		t.Fatalf("Init: %v", err)
+	}
+
+	missesBefore := testutil.ToFloat64(cacheMisses.WithLabelValues(name))
+	if c.Match(model.LabelSet{"env": "prod"}) {
+		t.Fatalf("expected non-match against the new matcher value after Init")
+	}
+	if got := testutil.ToFloat64(cacheMisses.WithLabelValues(name)); got != missesBefore+1 {
+		t.Fatalf("expected Init to force a fresh miss rather than serve a stale cached result, misses = %v, want %v", got, missesBefore+1)
+	}
+}
+
+// TestCachedMatcherConcurrent exercises Match from many goroutines at once,
+// to be run with -race: the cache is on the hot dispatcher/silence/
+// inhibition path and is accessed concurrently in production.
+func TestCachedMatcherConcurrent(t *testing.T) {
+	c := newTestCachedMatcher(t, "env", "prod", 4)
+
+	var wg sync.WaitGroup
+	values := []string{"prod", "staging", "dev", "qa", "canary"}
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			v := values[i%len(values)]
+			c.Match(model.LabelSet{"env": model.LabelValue(v)})
+		}(i)
+	}
+	wg.Wait()
+}
+
+// TestMatchersCachedShortCircuits checks that Matchers.Cached composes one
+// CachedMatcher per leaf and short-circuits on the first non-match, like
+// Matchers.Match.
+func TestMatchersCachedShortCircuits(t *testing.T) {
+	ms := Matchers{
+		NewMatcher("env", "prod"),
+		NewMatcher("severity", "critical"),
+	}
+	cached := ms.Cached(10)
+
+	if !cached.Match(model.LabelSet{"env": "prod", "severity": "critical"}) {
+		t.Fatalf("expected match when every Matcher is satisfied")
+	}
+	if cached.Match(model.LabelSet{"env": "prod", "severity": "warning"}) {
+		t.Fatalf("expected non-match when a later Matcher fails")
+	}
+
+	// When the first Matcher fails, the second leaf's cache sees no lookup.
+	severityHits := testutil.ToFloat64(cacheHits.WithLabelValues("severity"))
+	severityMisses := testutil.ToFloat64(cacheMisses.WithLabelValues("severity"))
+	if cached.Match(model.LabelSet{"env": "staging", "severity": "critical"}) {
+		t.Fatalf("expected non-match when the first Matcher fails")
+	}
+	if testutil.ToFloat64(cacheHits.WithLabelValues("severity")) != severityHits ||
+		testutil.ToFloat64(cacheMisses.WithLabelValues("severity")) != severityMisses {
+		t.Fatalf("second Matcher was evaluated after the first one failed")
+	}
+}
@@ -0,0 +1,142 @@
+// Copyright 2015 Prometheus Team
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package types
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// globPattern is one compiled sub-pattern of a MatchGlob Matcher.Value. Each
+// sub-pattern is translated once to an anchored RE2 regexp rather than
+// interpreted on every Match call.
+// ------------------------------------------------------------------------------
+// globPattern 是MatchGlob的Value里的一个子模式，编译一次后转换为锚定的RE2
+// 正则，避免每次Match都重新解析shell通配符。
+type globPattern struct {
+	re     *regexp.Regexp
+	negate bool
+}
+
+// compileGlobSet compiles a space-separated set of shell-style patterns into
+// a slice of globPattern. Each pattern may support "*" (any run of
+// characters), "?" (any single character) and "[...]" (a character class,
+// passed through to RE2 unchanged), and may be prefixed with "!" to negate
+// it. An empty value compiles to an empty set, which matches nothing.
+// ------------------------------------------------------------------------------
+// compileGlobSet 把空格分隔的一组shell风格通配符编译成globPattern切片。每个
+// 子模式支持"*"（任意长度字符）、"?"（单个字符）和"[...]"（字符类，原样
+// 传给RE2），并可以用前缀"!"表示取反。空Value编译为空集合，不匹配任何值。
+func compileGlobSet(value string) ([]globPattern, error) {
+	fields := strings.Fields(value)
+	patterns := make([]globPattern, 0, len(fields))
+	for _, f := range fields {
+		negate := false
+		if strings.HasPrefix(f, "!") {
+			negate = true
+			f = f[1:]
+		}
+		if f == "" {
+			return nil, fmt.Errorf("empty glob pattern in %q", value)
+		}
+		re, err := regexp.Compile("^(?:" + globToRegexp(f) + ")$")
+		if err != nil {
+			return nil, fmt.Errorf("invalid glob pattern %q: %w", f, err)
+		}
+		patterns = append(patterns, globPattern{re: re, negate: negate})
+	}
+	return patterns, nil
+}
+
+// globToRegexp translates a single shell-style glob into the body of an RE2
+// pattern (without the surrounding anchors). Runs of literal characters are
+// escaped with regexp.QuoteMeta; "*", "?" and "[...]" classes are passed
+// through with their regex equivalents.
+func globToRegexp(pattern string) string {
+	var buf strings.Builder
+	var lit strings.Builder
+	flushLit := func() {
+		if lit.Len() > 0 {
+			buf.WriteString(regexp.QuoteMeta(lit.String()))
+			lit.Reset()
+		}
+	}
+	for i := 0; i < len(pattern); i++ {
+		c := pattern[i]
+		switch c {
+		case '*':
+			flushLit()
+			buf.WriteString(".*")
+		case '?':
+			flushLit()
+			buf.WriteString(".")
+		case '[':
+			flushLit()
+			end := strings.IndexByte(pattern[i:], ']')
+			if end < 0 {
+				lit.WriteByte(c)
+				continue
+			}
+			buf.WriteString(pattern[i : i+end+1])
+			i += end
+		default:
+			lit.WriteByte(c)
+		}
+	}
+	flushLit()
+	return buf.String()
+}
+
+// ValidateGlobPattern reports whether value is a well-formed MatchGlob
+// pattern set, without keeping the compiled result around. It lets callers
+// outside this package (e.g. config.go's match_glob unmarshaling) reject a
+// malformed pattern at config-load time instead of at first Match.
+// ------------------------------------------------------------------------------
+// ValidateGlobPattern 检查value是否是合法的MatchGlob模式集合，但不保留编译
+// 结果，供本包之外的调用方（如config.go解析match_glob时）在加载配置阶段
+// 就能发现非法模式，而不是等到第一次Match才报错。
+func ValidateGlobPattern(value string) error {
+	_, err := compileGlobSet(value)
+	return err
+}
+
+// matchGlobSet reports whether v satisfies patterns: it matches if there are
+// no positive (non-negated) sub-patterns or at least one positive
+// sub-pattern matches v, AND no negated sub-pattern matches v. An empty set
+// matches nothing.
+// ------------------------------------------------------------------------------
+// matchGlobSet 判断v是否满足patterns：当没有正向子模式，或至少一个正向子
+// 模式匹配v时，视为匹配；同时要求没有任何取反子模式匹配v，两者都满足才算
+// 整体匹配。空集合不匹配任何值。
+func matchGlobSet(patterns []globPattern, v string) bool {
+	if len(patterns) == 0 {
+		return false
+	}
+	hasPositive := false
+	positiveMatched := false
+	for _, p := range patterns {
+		if p.negate {
+			if p.re.MatchString(v) {
+				return false
+			}
+			continue
+		}
+		hasPositive = true
+		if p.re.MatchString(v) {
+			positiveMatched = true
+		}
+	}
+	return !hasPositive || positiveMatched
+}
@@ -0,0 +1,87 @@
+// Copyright 2015 Prometheus Team
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package types
+
+import (
+	"testing"
+
+	"github.com/prometheus/common/model"
+)
+
+func TestMatchGlobSet(t *testing.T) {
+	tests := []struct {
+		name    string
+		pattern string
+		value   string
+		want    bool
+	}{
+		{"star prefix matches", "web-*", "web-01", true},
+		{"star prefix rejects", "web-*", "db-01", false},
+		{"question mark matches single char", "web-?", "web-1", true},
+		{"question mark rejects multiple chars", "web-?", "web-12", false},
+		{"character class matches", "web-[0-9]", "web-5", true},
+		{"character class rejects", "web-[0-9]", "web-a", false},
+		{"space separated alternatives, first matches", "web-* db-*", "web-01", true},
+		{"space separated alternatives, second matches", "web-* db-*", "db-01", true},
+		{"space separated alternatives, neither matches", "web-* db-*", "cache-01", false},
+		{"negated pattern excludes match", "web-* !web-canary", "web-canary", false},
+		{"negated pattern allows non-match", "web-* !web-canary", "web-01", true},
+		{"negation alone excludes only that value", "!web-canary", "web-canary", false},
+		{"negation alone matches everything else", "!web-canary", "web-01", true},
+		{"empty pattern matches nothing", "", "anything", false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			patterns, err := compileGlobSet(tc.pattern)
+			if err != nil {
+				t.Fatalf("compileGlobSet(%q): %v", tc.pattern, err)
+			}
+			if got := matchGlobSet(patterns, tc.value); got != tc.want {
+				t.Fatalf("matchGlobSet(%q, %q) = %v, want %v", tc.pattern, tc.value, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestCompileGlobSetRejectsEmptyAlternative(t *testing.T) {
+	if _, err := compileGlobSet("web-* !"); err == nil {
+		t.Fatalf("compileGlobSet(\"web-* !\") = nil error, want error for empty alternative")
+	}
+}
+
+func TestValidateGlobPattern(t *testing.T) {
+	if err := ValidateGlobPattern("web-*"); err != nil {
+		t.Fatalf("ValidateGlobPattern(%q): %v", "web-*", err)
+	}
+	if err := ValidateGlobPattern("web-[z-a]"); err == nil {
+		t.Fatalf("ValidateGlobPattern(%q) = nil error, want error for invalid character class range", "web-[z-a]")
+	}
+}
+
+func TestNewGlobMatcher(t *testing.T) {
+	m, err := NewGlobMatcher("instance", "web-*")
+	if err != nil {
+		t.Fatalf("NewGlobMatcher: %v", err)
+	}
+	if m.Type != MatchGlob {
+		t.Fatalf("Type = %v, want MatchGlob", m.Type)
+	}
+	if m.IsRegex {
+		t.Fatalf("IsRegex = true, want false for a glob matcher")
+	}
+	if !m.Match(model.LabelSet{"instance": "web-01"}) {
+		t.Fatalf("expected Match to succeed for web-01")
+	}
+}
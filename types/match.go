@@ -15,6 +15,7 @@ package types
 
 import (
 	"bytes"
+	"encoding/json"
 	"fmt"
 	"regexp"
 	"sort"
@@ -22,40 +23,90 @@ import (
 	"github.com/prometheus/common/model"
 )
 
+// MatchType is the type of comparison a Matcher performs between a label's
+// value and Matcher.Value.
+// ----------------------------------------------------------------------
+// MatchType 定义Matcher的比较方式，在原有的等值/正则匹配基础上，增加了
+// 它们各自的取反形式，对应pkg/labels.Matcher里更完整的匹配类型集合。
+type MatchType int
+
+// Possible MatchType values.
+const (
+	MatchEqual MatchType = iota
+	MatchNotEqual
+	MatchRegexEqual
+	MatchRegexNotEqual
+	// MatchGlob compares against a space-separated set of shell-style
+	// patterns (see glob.go), as a cheaper alternative to regex for the
+	// common "prefix*"/"prefix-* other-*" cases.
+	MatchGlob
+)
+
+func (m MatchType) String() string {
+	switch m {
+	case MatchEqual:
+		return "="
+	case MatchNotEqual:
+		return "!="
+	case MatchRegexEqual:
+		return "=~"
+	case MatchRegexNotEqual:
+		return "!~"
+	case MatchGlob:
+		return "=*"
+	}
+	panic("unknown match type")
+}
+
 // Matcher defines a matching rule for the value of a given label.
 // ----------------------------------------------------------------------
 // Matcher 匹配器包含标签名和标签的值，假如是值是一个正则表达式的话，
-// 会生成一个正则匹配器来进行匹配。
+// 会生成一个正则匹配器来进行匹配。Type决定了比较方式：等值、不等值、
+// 正则匹配、正则不匹配，以及glob通配符匹配。IsRegex字段仍然保留，仅为兼容旧版JSON而存在，
+// 新代码应该使用Type。
 type Matcher struct {
-	Name    string `json:"name"`    // 标签名
-	Value   string `json:"value"`   // 标签值
-	IsRegex bool   `json:"isRegex"` // 是否为正则
+	Type    MatchType `json:"-"`
+	Name    string    `json:"name"`    // 标签名
+	Value   string    `json:"value"`   // 标签值
+	IsRegex bool      `json:"isRegex"` // 是否为正则，已废弃，仅为兼容旧版JSON保留
+
+	regex      *regexp.Regexp // 正则匹配器
+	glob       []globPattern  // MatchGlob的编译结果，参见glob.go
+	generation uint64         // 每次Init递增，供cache.go里的CachedMatcher判断缓存是否过期
 
-	regex *regexp.Regexp // 正则匹配器
 }
 
 // Init internals of the Matcher. Must be called before using Match.
 // ----------------------------------------------------------------------
 // Init 匹配器 Matcher 的核心。必须在使用 Match 方法前调用此方法。会检查
 // 是否为正则类型的匹配。如果是的话，通过正则文字，生成正则匹配对象。
+// 每次调用都会递增generation，使任何包装该Matcher的CachedMatcher都能
+// 发现底层regex/value已经变化，从而丢弃过期的缓存结果。
 func (m *Matcher) Init() error {
-	if !m.IsRegex {
-		return nil
-	}
-	re, err := regexp.Compile("^(?:" + m.Value + ")$")
-	if err != nil {
-		return err
+	m.regex = nil
+	m.glob = nil
+	m.generation++
+	m.IsRegex = m.Type == MatchRegexEqual || m.Type == MatchRegexNotEqual
+	switch m.Type {
+	case MatchRegexEqual, MatchRegexNotEqual:
+		re, err := regexp.Compile("^(?:" + m.Value + ")$")
+		if err != nil {
+			return err
+		}
+		m.regex = re
+	case MatchGlob:
+		glob, err := compileGlobSet(m.Value)
+		if err != nil {
+			return err
+		}
+		m.glob = glob
 	}
-	m.regex = re
 	return nil
 }
 
 // String 方法
 func (m *Matcher) String() string {
-	if m.IsRegex {
-		return fmt.Sprintf("%s=~%q", m.Name, m.Value)
-	}
-	return fmt.Sprintf("%s=%q", m.Name, m.Value)
+	return fmt.Sprintf("%s%s%q", m.Name, m.Type, m.Value)
 }
 
 // Validate returns true if all fields of the matcher have valid values.
@@ -66,12 +117,21 @@ func (m *Matcher) Validate() error {
 	if !model.LabelName(m.Name).IsValid() {
 		return fmt.Errorf("invalid name %q", m.Name)
 	}
-	if m.IsRegex {
+	switch m.Type {
+	case MatchRegexEqual, MatchRegexNotEqual:
 		if _, err := regexp.Compile(m.Value); err != nil {
 			return fmt.Errorf("invalid regular expression %q", m.Value)
 		}
-	} else if !model.LabelValue(m.Value).IsValid() || len(m.Value) == 0 {
-		return fmt.Errorf("invalid value %q", m.Value)
+	case MatchEqual, MatchNotEqual:
+		if !model.LabelValue(m.Value).IsValid() || len(m.Value) == 0 {
+			return fmt.Errorf("invalid value %q", m.Value)
+		}
+	case MatchGlob:
+		if _, err := compileGlobSet(m.Value); err != nil {
+			return fmt.Errorf("invalid glob pattern %q: %w", m.Value, err)
+		}
+	default:
+		return fmt.Errorf("unknown match type %v", m.Type)
 	}
 	return nil
 }
@@ -86,12 +146,38 @@ func (m *Matcher) Match(lset model.LabelSet) bool {
 	// for the comparison below.
 	// ----------------------------------------------------------------
 	// 当标签是没设置的话，以下的匹配逻辑也是正常的。
-	v := lset[model.LabelName(m.Name)]
+	v := string(lset[model.LabelName(m.Name)])
+
+	switch m.Type {
+	case MatchEqual:
+		return v == m.Value
+	case MatchNotEqual:
+		return v != m.Value
+	case MatchRegexEqual:
+		return m.regex.MatchString(v)
+	case MatchRegexNotEqual:
+		return !m.regex.MatchString(v)
+	case MatchGlob:
+		return matchGlobSet(m.glob, v)
+	}
+	panic("unknown match type")
+}
 
-	if m.IsRegex {
-		return m.regex.MatchString(string(v))
+// NewGlobMatcher returns a new matcher that compares values against a
+// space-separated set of shell-style patterns (see glob.go). The matcher is
+// already initialized.
+// ------------------------------------------------------------------------------
+// NewGlobMatcher 生成一个glob模式的Matcher，语法见glob.go。构造时已完成Init。
+func NewGlobMatcher(name model.LabelName, pattern string) (*Matcher, error) {
+	m := &Matcher{
+		Type:  MatchGlob,
+		Name:  string(name),
+		Value: pattern,
+	}
+	if err := m.Init(); err != nil {
+		return nil, err
 	}
-	return string(v) == m.Value
+	return m, nil
 }
 
 // NewMatcher returns a new matcher that compares against equality of
@@ -101,9 +187,21 @@ func (m *Matcher) Match(lset model.LabelSet) bool {
 // 方法生成Matcher。
 func NewMatcher(name model.LabelName, value string) *Matcher {
 	return &Matcher{
-		Name:    string(name),
-		Value:   value,
-		IsRegex: false,
+		Type:  MatchEqual,
+		Name:  string(name),
+		Value: value,
+	}
+}
+
+// NewNotMatcher returns a new matcher that compares against inequality of
+// the given value.
+// ------------------------------------------------------------------------------
+// NewNotMatcher 生成一个"不等于"的Matcher，用于取反的匹配规则。
+func NewNotMatcher(name model.LabelName, value string) *Matcher {
+	return &Matcher{
+		Type:  MatchNotEqual,
+		Name:  string(name),
+		Value: value,
 	}
 }
 
@@ -115,6 +213,21 @@ func NewMatcher(name model.LabelName, value string) *Matcher {
 // NewRegexMatcher 返回一个正则的Matcher。根据正则表达对象，来生成匹配器。
 func NewRegexMatcher(name model.LabelName, re *regexp.Regexp) *Matcher {
 	return &Matcher{
+		Type:    MatchRegexEqual,
+		Name:    string(name),
+		Value:   re.String(),
+		IsRegex: true,
+		regex:   re,
+	}
+}
+
+// NewNotRegexMatcher returns a new matcher that compares values against the
+// negation of a regular expression. The matcher is already initialized.
+// ------------------------------------------------------------------------------
+// NewNotRegexMatcher 生成一个正则取反的Matcher，即标签值不匹配该正则时才命中。
+func NewNotRegexMatcher(name model.LabelName, re *regexp.Regexp) *Matcher {
+	return &Matcher{
+		Type:    MatchRegexNotEqual,
 		Name:    string(name),
 		Value:   re.String(),
 		IsRegex: true,
@@ -122,6 +235,62 @@ func NewRegexMatcher(name model.LabelName, re *regexp.Regexp) *Matcher {
 	}
 }
 
+// jsonMatcher is the on-the-wire representation of a Matcher. IsRegex is
+// kept for clients still speaking the pre-MatchType API; IsEqual is new and
+// defaults to true (equality) when absent, so old payloads without it still
+// decode as MatchEqual/MatchRegexEqual.
+type jsonMatcher struct {
+	Name    string `json:"name"`
+	Value   string `json:"value"`
+	IsRegex bool   `json:"isRegex"`
+	IsEqual *bool  `json:"isEqual,omitempty"`
+	IsGlob  bool   `json:"isGlob,omitempty"`
+}
+
+// MarshalJSON implements the json.Marshaler interface for Matcher, emitting
+// both the legacy isRegex field and the new isEqual field so that clients on
+// either side of the MatchType migration can decode the payload.
+func (m Matcher) MarshalJSON() ([]byte, error) {
+	isEqual := m.Type == MatchEqual || m.Type == MatchRegexEqual
+	return json.Marshal(jsonMatcher{
+		Name:    m.Name,
+		Value:   m.Value,
+		IsRegex: m.Type == MatchRegexEqual || m.Type == MatchRegexNotEqual,
+		IsEqual: &isEqual,
+		IsGlob:  m.Type == MatchGlob,
+	})
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface for Matcher. It
+// accepts payloads with or without isEqual, defaulting to equality so that
+// pre-MatchType clients keep working.
+func (m *Matcher) UnmarshalJSON(data []byte) error {
+	var aux jsonMatcher
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	isEqual := true
+	if aux.IsEqual != nil {
+		isEqual = *aux.IsEqual
+	}
+	switch {
+	case aux.IsGlob:
+		m.Type = MatchGlob
+	case aux.IsRegex && isEqual:
+		m.Type = MatchRegexEqual
+	case aux.IsRegex && !isEqual:
+		m.Type = MatchRegexNotEqual
+	case !aux.IsRegex && isEqual:
+		m.Type = MatchEqual
+	case !aux.IsRegex && !isEqual:
+		m.Type = MatchNotEqual
+	}
+	m.Name = aux.Name
+	m.Value = aux.Value
+	m.IsRegex = aux.IsRegex
+	return nil
+}
+
 // Matchers provides the Match and Fingerprint methods for a slice of Matchers.
 // Matchers must always be sorted.
 // ------------------------------------------------------------------------------
@@ -138,7 +307,7 @@ func NewMatchers(ms ...*Matcher) Matchers {
 	return m
 }
 
-//----------------------- sort.Sort 接口方法 -----------------------
+// ----------------------- sort.Sort 接口方法 -----------------------
 func (ms Matchers) Len() int      { return len(ms) }
 func (ms Matchers) Swap(i, j int) { ms[i], ms[j] = ms[j], ms[i] }
 
@@ -155,7 +324,7 @@ func (ms Matchers) Less(i, j int) bool {
 	if ms[i].Value < ms[j].Value {
 		return true
 	}
-	return !ms[i].IsRegex && ms[j].IsRegex
+	return ms[i].Type < ms[j].Type
 }
 
 //----------------------- sort.Sort 接口方法 end -----------------------
@@ -0,0 +1,303 @@
+// Copyright 2015 Prometheus Team
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package types
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/prometheus/common/model"
+)
+
+// MatcherExpr is a boolean combination of Matchers. Unlike Matchers, which
+// always ANDs every element, a MatcherExpr tree can express arbitrary
+// AND/OR/NOT combinations, e.g. "env=prod AND (severity=critical OR
+// team=~payments-.*)". Anything that currently accepts a Matchers value
+// (routing tree, silences, inhibition rules) can accept a MatcherExpr
+// instead; Matchers.Expr wraps a flat Matchers as an equivalent AndExpr for
+// callers migrating incrementally.
+// -------------------------------------------------------------------------
+// MatcherExpr 是Matcher的布尔组合。和总是"与"的Matchers不同，MatcherExpr
+// 树可以表达任意的与/或/非组合，例如"env=prod 且 (severity=critical 或
+// team=~payments-.*)"。路由树、静默、抑制规则里原本接收Matchers的地方，
+// 都可以改为接收MatcherExpr；Matchers.Expr方法把一个扁平的Matchers包装成
+// 等价的AndExpr，方便逐步迁移而不必一次性改动调用方。
+type MatcherExpr interface {
+	Match(lset model.LabelSet) bool
+	String() string
+}
+
+// LeafExpr wraps a single *Matcher as a MatcherExpr.
+type LeafExpr struct {
+	M *Matcher
+}
+
+// NewLeafExpr returns a MatcherExpr wrapping m.
+func NewLeafExpr(m *Matcher) *LeafExpr {
+	return &LeafExpr{M: m}
+}
+
+// Match implements MatcherExpr.
+func (e *LeafExpr) Match(lset model.LabelSet) bool { return e.M.Match(lset) }
+
+// String implements MatcherExpr.
+func (e *LeafExpr) String() string { return e.M.String() }
+
+// AndExpr matches when every child expression matches.
+type AndExpr struct {
+	Exprs []MatcherExpr
+}
+
+// Match implements MatcherExpr.
+func (e *AndExpr) Match(lset model.LabelSet) bool {
+	for _, x := range e.Exprs {
+		if !x.Match(lset) {
+			return false
+		}
+	}
+	return true
+}
+
+// String implements MatcherExpr.
+func (e *AndExpr) String() string { return "and(" + joinExprs(e.Exprs) + ")" }
+
+// OrExpr matches when any child expression matches.
+type OrExpr struct {
+	Exprs []MatcherExpr
+}
+
+// Match implements MatcherExpr.
+func (e *OrExpr) Match(lset model.LabelSet) bool {
+	for _, x := range e.Exprs {
+		if x.Match(lset) {
+			return true
+		}
+	}
+	return false
+}
+
+// String implements MatcherExpr.
+func (e *OrExpr) String() string { return "or(" + joinExprs(e.Exprs) + ")" }
+
+// NotExpr matches when its child expression does not match.
+type NotExpr struct {
+	Expr MatcherExpr
+}
+
+// Match implements MatcherExpr.
+func (e *NotExpr) Match(lset model.LabelSet) bool { return !e.Expr.Match(lset) }
+
+// String implements MatcherExpr.
+func (e *NotExpr) String() string { return "not(" + e.Expr.String() + ")" }
+
+func joinExprs(exprs []MatcherExpr) string {
+	parts := make([]string, len(exprs))
+	for i, e := range exprs {
+		parts[i] = e.String()
+	}
+	return strings.Join(parts, ", ")
+}
+
+// Expr returns ms as an equivalent AndExpr, so call sites that already
+// accept a MatcherExpr can be handed a flat Matchers value unchanged.
+func (ms Matchers) Expr() MatcherExpr {
+	exprs := make([]MatcherExpr, len(ms))
+	for i, m := range ms {
+		exprs[i] = NewLeafExpr(m)
+	}
+	return &AndExpr{Exprs: exprs}
+}
+
+// ParseMatcherExpr parses the textual grammar
+// `and(a="1", or(b="2", not(c=~"3.*")))` into a MatcherExpr tree. Leaf
+// matchers use the same `name<op>"value"` syntax as Matcher.String, with op
+// one of =, !=, =~, !~, =*, so String() round-trips through ParseMatcherExpr.
+func ParseMatcherExpr(s string) (MatcherExpr, error) {
+	p := &exprParser{input: s}
+	p.skipSpace()
+	expr, err := p.parseExpr()
+	if err != nil {
+		return nil, err
+	}
+	p.skipSpace()
+	if p.pos != len(p.input) {
+		return nil, fmt.Errorf("unexpected trailing input %q", p.input[p.pos:])
+	}
+	return expr, nil
+}
+
+type exprParser struct {
+	input string
+	pos   int
+}
+
+func (p *exprParser) skipSpace() {
+	for p.pos < len(p.input) {
+		switch p.input[p.pos] {
+		case ' ', '\t', '\n', '\r':
+			p.pos++
+		default:
+			return
+		}
+	}
+}
+
+func (p *exprParser) parseExpr() (MatcherExpr, error) {
+	p.skipSpace()
+	ident, err := p.parseIdent()
+	if err != nil {
+		return nil, err
+	}
+	p.skipSpace()
+	if p.pos < len(p.input) && p.input[p.pos] == '(' {
+		switch ident {
+		case "and", "or", "not":
+			return p.parseFuncArgs(ident)
+		default:
+			return nil, fmt.Errorf("unknown function %q", ident)
+		}
+	}
+	return p.parseLeaf(ident)
+}
+
+func (p *exprParser) parseIdent() (string, error) {
+	start := p.pos
+	for p.pos < len(p.input) {
+		c := p.input[p.pos]
+		if (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9') || c == '_' || c == ':' {
+			p.pos++
+			continue
+		}
+		break
+	}
+	if p.pos == start {
+		return "", fmt.Errorf("expected identifier at position %d", start)
+	}
+	return p.input[start:p.pos], nil
+}
+
+func (p *exprParser) parseFuncArgs(name string) (MatcherExpr, error) {
+	p.pos++ // consume '('
+	var args []MatcherExpr
+	p.skipSpace()
+	if p.pos < len(p.input) && p.input[p.pos] == ')' {
+		p.pos++
+	} else {
+		for {
+			arg, err := p.parseExpr()
+			if err != nil {
+				return nil, err
+			}
+			args = append(args, arg)
+			p.skipSpace()
+			if p.pos >= len(p.input) {
+				return nil, fmt.Errorf("unexpected end of input, expected ',' or ')'")
+			}
+			switch p.input[p.pos] {
+			case ',':
+				p.pos++
+				continue
+			case ')':
+				p.pos++
+			default:
+				return nil, fmt.Errorf("expected ',' or ')' at position %d", p.pos)
+			}
+			break
+		}
+	}
+	switch name {
+	case "and":
+		if len(args) == 0 {
+			return nil, fmt.Errorf("and() requires at least one argument")
+		}
+		return &AndExpr{Exprs: args}, nil
+	case "or":
+		if len(args) == 0 {
+			return nil, fmt.Errorf("or() requires at least one argument")
+		}
+		return &OrExpr{Exprs: args}, nil
+	case "not":
+		if len(args) != 1 {
+			return nil, fmt.Errorf("not() requires exactly one argument")
+		}
+		return &NotExpr{Expr: args[0]}, nil
+	}
+	panic("unreachable")
+}
+
+func (p *exprParser) parseLeaf(name string) (MatcherExpr, error) {
+	p.skipSpace()
+	op, err := p.parseOp()
+	if err != nil {
+		return nil, err
+	}
+	p.skipSpace()
+	value, err := p.parseString()
+	if err != nil {
+		return nil, err
+	}
+	m := &Matcher{Name: name, Value: value, Type: op}
+	if err := m.Init(); err != nil {
+		return nil, err
+	}
+	return &LeafExpr{M: m}, nil
+}
+
+func (p *exprParser) parseOp() (MatchType, error) {
+	rest := p.input[p.pos:]
+	switch {
+	case strings.HasPrefix(rest, "=~"):
+		p.pos += 2
+		return MatchRegexEqual, nil
+	case strings.HasPrefix(rest, "!~"):
+		p.pos += 2
+		return MatchRegexNotEqual, nil
+	case strings.HasPrefix(rest, "!="):
+		p.pos += 2
+		return MatchNotEqual, nil
+	case strings.HasPrefix(rest, "=*"):
+		p.pos += 2
+		return MatchGlob, nil
+	case strings.HasPrefix(rest, "="):
+		p.pos++
+		return MatchEqual, nil
+	}
+	return 0, fmt.Errorf("expected match operator at position %d", p.pos)
+}
+
+func (p *exprParser) parseString() (string, error) {
+	if p.pos >= len(p.input) || p.input[p.pos] != '"' {
+		return "", fmt.Errorf("expected quoted string at position %d", p.pos)
+	}
+	start := p.pos
+	p.pos++
+	for p.pos < len(p.input) {
+		switch p.input[p.pos] {
+		case '\\':
+			p.pos += 2
+		case '"':
+			p.pos++
+			raw := p.input[start:p.pos]
+			s, err := strconv.Unquote(raw)
+			if err != nil {
+				return "", fmt.Errorf("invalid quoted string %q: %w", raw, err)
+			}
+			return s, nil
+		default:
+			p.pos++
+		}
+	}
+	return "", fmt.Errorf("unterminated string starting at position %d", start)
+}
@@ -0,0 +1,142 @@
+// Copyright 2015 Prometheus Team
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package types
+
+import (
+	"testing"
+
+	"github.com/prometheus/common/model"
+)
+
+// TestParseMatcherExprRoundTrip checks that String() after Parse() is
+// stable for nested and/or/not combinations, and that the parsed tree
+// matches label sets as expected.
+func TestParseMatcherExprRoundTrip(t *testing.T) {
+	cases := []struct {
+		name  string
+		input string
+		lset  model.LabelSet
+		want  bool
+	}{
+		{
+			name:  "single leaf",
+			input: `env="prod"`,
+			lset:  model.LabelSet{"env": "prod"},
+			want:  true,
+		},
+		{
+			name:  "and of two leaves",
+			input: `and(env="prod", severity="critical")`,
+			lset:  model.LabelSet{"env": "prod", "severity": "critical"},
+			want:  true,
+		},
+		{
+			name:  "and fails when one leaf mismatches",
+			input: `and(env="prod", severity="critical")`,
+			lset:  model.LabelSet{"env": "prod", "severity": "warning"},
+			want:  false,
+		},
+		{
+			name:  "or of two leaves",
+			input: `or(env="prod", env="staging")`,
+			lset:  model.LabelSet{"env": "staging"},
+			want:  true,
+		},
+		{
+			name:  "not negates",
+			input: `not(env="prod")`,
+			lset:  model.LabelSet{"env": "staging"},
+			want:  true,
+		},
+		{
+			name:  "nested and/or/not",
+			input: `and(env="prod", or(severity="critical", not(team=~"payments-.*")))`,
+			lset:  model.LabelSet{"env": "prod", "severity": "warning", "team": "billing"},
+			want:  true,
+		},
+		{
+			name:  "nested and/or/not, no branch matches",
+			input: `and(env="prod", or(severity="critical", not(team=~"payments-.*")))`,
+			lset:  model.LabelSet{"env": "prod", "severity": "warning", "team": "payments-core"},
+			want:  false,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			expr, err := ParseMatcherExpr(c.input)
+			if err != nil {
+				t.Fatalf("ParseMatcherExpr(%q): %v", c.input, err)
+			}
+			if got := expr.Match(c.lset); got != c.want {
+				t.Fatalf("Match(%v) = %v, want %v", c.lset, got, c.want)
+			}
+
+			// String() after Parse() must itself re-parse to an
+			// equivalent tree, i.e. the grammar round-trips.
+			expr2, err := ParseMatcherExpr(expr.String())
+			if err != nil {
+				t.Fatalf("ParseMatcherExpr(expr.String()) = %q: %v", expr.String(), err)
+			}
+			if got := expr2.Match(c.lset); got != c.want {
+				t.Fatalf("round-tripped expr Match(%v) = %v, want %v", c.lset, got, c.want)
+			}
+		})
+	}
+}
+
+// TestParseMatcherExprMalformed checks that malformed input is rejected
+// rather than silently mis-parsed.
+func TestParseMatcherExprMalformed(t *testing.T) {
+	cases := []string{
+		``,
+		`env=`,
+		`env="prod"extra`,
+		`and(env="prod"`,
+		`and()`,
+		`or()`,
+		`not()`,
+		`not(env="a", env="b")`,
+		`nand(env="a")`,
+		`env=prod`,
+		`env="unterminated`,
+	}
+	for _, in := range cases {
+		t.Run(in, func(t *testing.T) {
+			if _, err := ParseMatcherExpr(in); err == nil {
+				t.Fatalf("ParseMatcherExpr(%q) succeeded, want error", in)
+			}
+		})
+	}
+}
+
+// TestMatchersExprAndsFlatMatchers checks that Matchers.Expr produces an
+// AndExpr equivalent to the flat Matchers it was derived from.
+func TestMatchersExprAndsFlatMatchers(t *testing.T) {
+	ms := Matchers{
+		NewMatcher("env", "prod"),
+		NewMatcher("severity", "critical"),
+	}
+
+	expr := ms.Expr()
+	if _, ok := expr.(*AndExpr); !ok {
+		t.Fatalf("Matchers.Expr() = %T, want *AndExpr", expr)
+	}
+	if !expr.Match(model.LabelSet{"env": "prod", "severity": "critical"}) {
+		t.Fatalf("expected Expr() to match a label set satisfying every Matcher")
+	}
+	if expr.Match(model.LabelSet{"env": "prod", "severity": "warning"}) {
+		t.Fatalf("expected Expr() to reject a label set missing one Matcher")
+	}
+}
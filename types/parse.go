@@ -0,0 +1,202 @@
+// Copyright 2015 Prometheus Team
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package types
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/prometheus/common/model"
+)
+
+// ParseMatchers parses a comma-separated list of matchers in the same
+// syntax Matchers.String formats, optionally wrapped in braces, e.g.
+// `{foo="bar", baz=~"qux.*"}` or `foo="bar", baz=~"qux.*"`. It is the
+// inverse of Matchers.String: for any Matchers value ms built from valid
+// matchers, ParseMatchers(ms.String()) returns an equivalent, sorted
+// Matchers. Callers that currently hand-roll splitting of matcher strings
+// from CLI flags, API query params or config should parse through here
+// instead.
+// -------------------------------------------------------------------------
+// ParseMatchers 解析逗号分隔的匹配器列表，语法和Matchers.String生成的格式
+// 一致，外层大括号可选，例如`{foo="bar", baz=~"qux.*"}`或者不带大括号的
+// `foo="bar", baz=~"qux.*"`。对于任意由合法Matcher构成的Matchers值ms，
+// ParseMatchers(ms.String())应当返回等价的、排序后的Matchers，是
+// Matchers.String的逆操作。凡是目前自行拆分匹配器字符串的调用方（CLI参数、
+// API查询参数、配置文件），都应该改为统一调用这里的解析器。
+func ParseMatchers(s string) (Matchers, error) {
+	p := &matcherParser{input: s}
+	p.skipSpace()
+	braced := false
+	if p.pos < len(p.input) && p.input[p.pos] == '{' {
+		braced = true
+		p.pos++
+	}
+
+	var ms Matchers
+	p.skipSpace()
+	for p.pos < len(p.input) && p.input[p.pos] != '}' {
+		m, err := p.parseMatcher()
+		if err != nil {
+			return nil, err
+		}
+		ms = append(ms, m)
+		p.skipSpace()
+		if p.pos < len(p.input) && p.input[p.pos] == ',' {
+			p.pos++
+			p.skipSpace()
+			continue
+		}
+		break
+	}
+
+	p.skipSpace()
+	if braced {
+		if p.pos >= len(p.input) || p.input[p.pos] != '}' {
+			return nil, fmt.Errorf("expected '}' at position %d", p.pos)
+		}
+		p.pos++
+		p.skipSpace()
+	}
+	if p.pos != len(p.input) {
+		return nil, fmt.Errorf("unexpected trailing input %q", p.input[p.pos:])
+	}
+
+	return NewMatchers(ms...), nil
+}
+
+// ParseMatcher parses a single `name<op>"value"` matcher, with op one of
+// =, !=, =~, !~, =*, e.g. `severity=~"critical|warning"`.
+func ParseMatcher(s string) (*Matcher, error) {
+	p := &matcherParser{input: s}
+	p.skipSpace()
+	m, err := p.parseMatcher()
+	if err != nil {
+		return nil, err
+	}
+	p.skipSpace()
+	if p.pos != len(p.input) {
+		return nil, fmt.Errorf("unexpected trailing input %q", p.input[p.pos:])
+	}
+	return m, nil
+}
+
+type matcherParser struct {
+	input string
+	pos   int
+}
+
+func (p *matcherParser) skipSpace() {
+	for p.pos < len(p.input) {
+		switch p.input[p.pos] {
+		case ' ', '\t', '\n', '\r':
+			p.pos++
+		default:
+			return
+		}
+	}
+}
+
+func (p *matcherParser) parseMatcher() (*Matcher, error) {
+	name, err := p.parseName()
+	if err != nil {
+		return nil, err
+	}
+	p.skipSpace()
+	op, err := p.parseOp()
+	if err != nil {
+		return nil, err
+	}
+	p.skipSpace()
+	value, err := p.parseQuoted()
+	if err != nil {
+		return nil, err
+	}
+	m := &Matcher{Name: name, Value: value, Type: op}
+	if err := m.Init(); err != nil {
+		return nil, err
+	}
+	if err := m.Validate(); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (p *matcherParser) parseName() (string, error) {
+	start := p.pos
+	for p.pos < len(p.input) {
+		c := p.input[p.pos]
+		if (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9') || c == '_' || c == ':' {
+			p.pos++
+			continue
+		}
+		break
+	}
+	if p.pos == start {
+		return "", fmt.Errorf("expected label name at position %d", start)
+	}
+	name := p.input[start:p.pos]
+	if !model.LabelName(name).IsValid() {
+		return "", fmt.Errorf("invalid label name %q", name)
+	}
+	return name, nil
+}
+
+func (p *matcherParser) parseOp() (MatchType, error) {
+	rest := p.input[p.pos:]
+	switch {
+	case strings.HasPrefix(rest, "=~"):
+		p.pos += 2
+		return MatchRegexEqual, nil
+	case strings.HasPrefix(rest, "!~"):
+		p.pos += 2
+		return MatchRegexNotEqual, nil
+	case strings.HasPrefix(rest, "!="):
+		p.pos += 2
+		return MatchNotEqual, nil
+	case strings.HasPrefix(rest, "=*"):
+		p.pos += 2
+		return MatchGlob, nil
+	case strings.HasPrefix(rest, "="):
+		p.pos++
+		return MatchEqual, nil
+	}
+	return 0, fmt.Errorf("expected match operator (=, !=, =~, !~, =*) at position %d", p.pos)
+}
+
+func (p *matcherParser) parseQuoted() (string, error) {
+	if p.pos >= len(p.input) || p.input[p.pos] != '"' {
+		return "", fmt.Errorf("expected quoted string at position %d", p.pos)
+	}
+	start := p.pos
+	p.pos++
+	for p.pos < len(p.input) {
+		switch p.input[p.pos] {
+		case '\\':
+			p.pos += 2
+		case '"':
+			p.pos++
+			raw := p.input[start:p.pos]
+			s, err := strconv.Unquote(raw)
+			if err != nil {
+				return "", fmt.Errorf("invalid quoted string %q: %w", raw, err)
+			}
+			return s, nil
+		default:
+			p.pos++
+		}
+	}
+	return "", fmt.Errorf("unterminated string starting at position %d", start)
+}
@@ -0,0 +1,73 @@
+// Copyright 2015 Prometheus Team
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package types
+
+import (
+	"regexp"
+	"testing"
+)
+
+// TestParseMatchersRoundTrip checks that ParseMatchers(ms.String()) is
+// equivalent to ms for a matcher of every MatchType, including MatchGlob --
+// the gap that let NewGlobMatcher's output fail to parse back.
+func TestParseMatchersRoundTrip(t *testing.T) {
+	re := regexp.MustCompile("^(?:critical|warning)$")
+	glob, err := NewGlobMatcher("instance", "web-*")
+	if err != nil {
+		t.Fatalf("NewGlobMatcher: %v", err)
+	}
+
+	ms := NewMatchers(
+		NewMatcher("severity", "critical"),
+		NewNotMatcher("severity", "info"),
+		NewRegexMatcher("severity", re),
+		NewNotRegexMatcher("severity", re),
+		glob,
+	)
+
+	got, err := ParseMatchers(ms.String())
+	if err != nil {
+		t.Fatalf("ParseMatchers(%q): %v", ms.String(), err)
+	}
+	if got.String() != ms.String() {
+		t.Fatalf("round-trip mismatch: ParseMatchers(%q).String() = %q", ms.String(), got.String())
+	}
+}
+
+// TestParseMatcherGlob is the concrete repro from the review: a glob
+// matcher's own String() output must parse back into an equivalent
+// MatchGlob matcher.
+func TestParseMatcherGlob(t *testing.T) {
+	want, err := NewGlobMatcher("instance", "web-*")
+	if err != nil {
+		t.Fatalf("NewGlobMatcher: %v", err)
+	}
+
+	got, err := ParseMatcher(want.String())
+	if err != nil {
+		t.Fatalf("ParseMatcher(%q): %v", want.String(), err)
+	}
+	if got.Type != MatchGlob {
+		t.Fatalf("Type = %v, want MatchGlob", got.Type)
+	}
+	if got.Name != "instance" || got.Value != "web-*" {
+		t.Fatalf("got = %+v, want Name=instance Value=web-*", got)
+	}
+}
+
+func TestParseMatcherUnknownOperator(t *testing.T) {
+	if _, err := ParseMatcher(`instance@"web-01"`); err == nil {
+		t.Fatalf("ParseMatcher() = nil error, want error for an unknown operator")
+	}
+}